@@ -0,0 +1,179 @@
+package go_sd_jwt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/utils"
+)
+
+// BatchIssuanceOptions configures how IssueBatch issues copies of a credential.
+type BatchIssuanceOptions struct {
+	IssuanceOptions
+	// HolderKeys are the public keys the copies are bound to, one copy is issued per key
+	HolderKeys []crypto.PublicKey
+	// Jkt binds each copy with the JWK thumbprint of its holder key rather than the key itself
+	Jkt bool
+	// IatJitter is the longest time the iat claim of a copy is moved back by, defaults to DefaultIatJitter
+	IatJitter time.Duration
+}
+
+// DefaultIatJitter is the IatJitter used when none is provided
+const DefaultIatJitter = time.Hour
+
+// IssueBatch issues one copy of the provided claims for each holder key, for wallets which present a different copy to
+// each verifier so that presentations cannot be linked.
+// Every copy is bound to its own holder key through the cnf claim and has its own salts and decoy digests, an error is
+// returned if a salt would be used more than once across the batch. Holder keys must be unique.
+// When the copies carry an iat claim, either from the claims or added through opts.Validity, each copy gets its own
+// random iat up to opts.IatJitter before it so that copies cannot be correlated by their issuance time. The nbf and exp
+// claims, whether provided in the claims or added through opts.Validity, are moved by the same offset as the iat of
+// their copy, so every copy keeps the validity window of the claims.
+func IssueBatch(claims map[string]any, opts BatchIssuanceOptions) ([]*SdJwt, error) {
+	if len(opts.HolderKeys) == 0 {
+		return nil, fmt.Errorf("%wat least one holder key must be provided", e.ErrInvalidIssuance)
+	}
+	if opts.Cnf != nil {
+		return nil, fmt.Errorf("%wCnf must not be set for batch issuance, each copy is bound to its own holder key", e.ErrInvalidIssuance)
+	}
+	if opts.IatJitter < 0 {
+		return nil, fmt.Errorf("%wiat jitter must not be negative", e.ErrInvalidIssuance)
+	}
+
+	thumbprints := make(map[string]bool, len(opts.HolderKeys))
+	for i, holderKey := range opts.HolderKeys {
+		thumbprint, err := JwkThumbprint(holderKey)
+		if err != nil {
+			return nil, fmt.Errorf("%winvalid holder key %d: %s", e.ErrInvalidIssuance, i, err.Error())
+		}
+		if thumbprints[thumbprint] {
+			return nil, fmt.Errorf("%wholder key %d is used more than once", e.ErrInvalidIssuance, i)
+		}
+		thumbprints[thumbprint] = true
+	}
+
+	iatJitter := opts.IatJitter
+	if iatJitter == 0 {
+		iatJitter = DefaultIatJitter
+	}
+
+	// the iat every copy is jittered from, if the copies have one
+	var baseIat *float64
+	if v, ok := claims["iat"]; ok {
+		iat, ok := utils.NumericDate(v)
+		if !ok {
+			return nil, fmt.Errorf("%wiat claim must be a numeric date", e.ErrInvalidIssuance)
+		}
+		baseIat = utils.Pointer(iat)
+	} else if opts.Validity.enabled() {
+		baseIat = utils.Pointer(float64(opts.Validity.Now().Unix()))
+	}
+	for _, name := range []string{"nbf", "exp"} {
+		if v, ok := claims[name]; ok {
+			if _, ok := utils.NumericDate(v); !ok {
+				return nil, fmt.Errorf("%w%s claim must be a numeric date", e.ErrInvalidIssuance, name)
+			}
+		}
+	}
+
+	salts := opts.SaltSource
+	if salts == nil {
+		salts = RandomSaltSource{}
+	}
+	tracked := &uniqueSaltSource{source: salts, seen: map[string]bool{}}
+
+	copies := make([]*SdJwt, len(opts.HolderKeys))
+	for i, holderKey := range opts.HolderKeys {
+		issuance := opts.IssuanceOptions
+		issuance.SaltSource = tracked
+
+		var err error
+		if opts.Jkt {
+			issuance.Cnf, err = NewJktConfirmation(holderKey)
+		} else {
+			issuance.Cnf, err = NewJwkConfirmation(holderKey)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%winvalid holder key %d: %s", e.ErrInvalidIssuance, i, err.Error())
+		}
+
+		copyClaims := claims
+		if baseIat != nil {
+			iat, err := jitter(utils.NumericTime(*baseIat), iatJitter)
+			if err != nil {
+				return nil, fmt.Errorf("error generating iat: %w", err)
+			}
+			copyIat := opts.Validity.round(iat).Unix()
+			offset := float64(copyIat) - *baseIat
+
+			copyClaims = make(map[string]any, len(claims)+1)
+			for k, v := range claims {
+				copyClaims[k] = v
+			}
+			copyClaims["iat"] = copyIat
+			for _, name := range []string{"nbf", "exp"} {
+				if v, ok := claims[name]; ok {
+					n, _ := utils.NumericDate(v)
+					copyClaims[name] = numericDateValue(n + offset)
+				}
+			}
+		}
+
+		copies[i], err = Issue(copyClaims, issuance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to issue copy %d: %w", i, err)
+		}
+	}
+	return copies, nil
+}
+
+// jitter returns a random time up to max before t, with a resolution of one second
+func jitter(t time.Time, max time.Duration) (time.Time, error) {
+	seconds := int64(max / time.Second)
+	if seconds == 0 {
+		return t, nil
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(seconds+1))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.Add(-time.Duration(n.Int64()) * time.Second), nil
+}
+
+// numericDateValue returns the NumericDate claim value for n, an integer unless n has a fraction of a second
+func numericDateValue(n float64) any {
+	if n == math.Trunc(n) {
+		return int64(n)
+	}
+	return n
+}
+
+// uniqueSaltSource wraps a SaltSource, returning an error if the wrapped source produces the same salt more than once
+type uniqueSaltSource struct {
+	source SaltSource
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func (u *uniqueSaltSource) NewSalt() (string, error) {
+	salt, err := u.source.NewSalt()
+	if err != nil {
+		return "", err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.seen[salt] {
+		return "", errors.New("salt reused across the batch")
+	}
+	u.seen[salt] = true
+	return salt, nil
+}
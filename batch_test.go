@@ -0,0 +1,295 @@
+package go_sd_jwt_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func holderKeys(t *testing.T, n int) ([]*ecdsa.PrivateKey, []crypto.PublicKey) {
+	private := make([]*ecdsa.PrivateKey, n)
+	public := make([]crypto.PublicKey, n)
+	for i := range private {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		private[i] = key
+		public[i] = &key.PublicKey
+	}
+	return private, public
+}
+
+type constantSaltSource struct{}
+
+func (constantSaltSource) NewSalt() (string, error) {
+	return "c2FsdHNhbHRzYWx0c2FsdA", nil
+}
+
+func TestIssueBatch(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	private, public := holderKeys(t, 3)
+
+	claims := issuanceClaims()
+	claims["iat"] = 1700000000
+	copies, err := go_sd_jwt.IssueBatch(claims, go_sd_jwt.BatchIssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			SelectivelyDisclosable: []string{"given_name", "family_name", "address", "nationalities[*]"},
+			DecoyDigests:           go_sd_jwt.DecoyDigests{Min: 2, Max: 4},
+		},
+		HolderKeys: public,
+		IatJitter:  time.Hour,
+	})
+	require.NoError(t, err)
+	require.Len(t, copies, 3)
+
+	salts := map[string]bool{}
+	digests := map[string]bool{}
+	for i, sdJwt := range copies {
+		require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: issuerSigner.Public()}))
+
+		cnf, err := sdJwt.Confirmation()
+		require.NoError(t, err)
+		expected, err := go_sd_jwt.NewJwkConfirmation(&private[i].PublicKey)
+		require.NoError(t, err)
+		assert.Equal(t, roundTrip(t, expected.Jwk), cnf.Jwk)
+
+		iat := int64(sdJwt.Body["iat"].(float64))
+		assert.LessOrEqual(t, iat, int64(1700000000))
+		assert.GreaterOrEqual(t, iat, int64(1700000000-3600))
+
+		for _, d := range sdJwt.Disclosures {
+			assert.False(t, salts[d.Salt], "salt %s is shared between copies", d.Salt)
+			salts[d.Salt] = true
+		}
+		for _, digest := range sdJwt.Body["_sd"].([]any) {
+			assert.False(t, digests[digest.(string)], "digest %s is shared between copies", digest)
+			digests[digest.(string)] = true
+		}
+
+		disclosed, err := sdJwt.GetDisclosedClaims()
+		require.NoError(t, err)
+		delete(disclosed, "cnf")
+		delete(disclosed, "iat")
+		expectedClaims := roundTrip(t, claims)
+		delete(expectedClaims, "iat")
+		assert.Equal(t, expectedClaims, disclosed)
+
+		presented := present(t, sdJwt, private[i])
+		require.NoError(t, presented.Verify(go_sd_jwt.VerificationOptions{VerifyKBJwtSignature: true}))
+	}
+	assert.Equal(t, 1700000000, claims["iat"], "the provided claims should not be modified")
+}
+
+func TestIssueBatch_Jkt(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	private, public := holderKeys(t, 2)
+
+	copies, err := go_sd_jwt.IssueBatch(issuanceClaims(), go_sd_jwt.BatchIssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner, SelectivelyDisclosable: []string{"given_name"}},
+		HolderKeys:      public,
+		Jkt:             true,
+	})
+	require.NoError(t, err)
+
+	for i, sdJwt := range copies {
+		thumbprint, err := go_sd_jwt.JwkThumbprint(public[i])
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"jkt": thumbprint}, sdJwt.Body["cnf"])
+		assert.NotContains(t, sdJwt.Body, "iat")
+
+		presented := present(t, sdJwt, private[i])
+		require.NoError(t, presented.Verify(go_sd_jwt.VerificationOptions{VerifyKBJwtSignature: true}))
+	}
+}
+
+func TestIssueBatch_Errors(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	_, public := holderKeys(t, 2)
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+		opts   go_sd_jwt.BatchIssuanceOptions
+		err    string
+	}{
+		{
+			name: "no holder keys",
+			opts: go_sd_jwt.BatchIssuanceOptions{},
+			err:  "invalid issuance: at least one holder key must be provided",
+		},
+		{
+			name: "cnf set",
+			opts: go_sd_jwt.BatchIssuanceOptions{
+				IssuanceOptions: go_sd_jwt.IssuanceOptions{Cnf: &go_sd_jwt.Confirmation{Kid: "holder-key-1"}},
+				HolderKeys:      public,
+			},
+			err: "invalid issuance: Cnf must not be set for batch issuance, each copy is bound to its own holder key",
+		},
+		{
+			name: "duplicate holder key",
+			opts: go_sd_jwt.BatchIssuanceOptions{HolderKeys: []crypto.PublicKey{public[0], public[1], public[0]}},
+			err:  "invalid issuance: holder key 2 is used more than once",
+		},
+		{
+			name: "invalid holder key",
+			opts: go_sd_jwt.BatchIssuanceOptions{HolderKeys: []crypto.PublicKey{"key"}},
+			err:  "invalid issuance: invalid holder key 0: failed to convert holder public key to a jwk: unknown public key format provided",
+		},
+		{
+			name: "negative jitter",
+			opts: go_sd_jwt.BatchIssuanceOptions{HolderKeys: public, IatJitter: -time.Second},
+			err:  "invalid issuance: iat jitter must not be negative",
+		},
+		{
+			name:   "non numeric iat",
+			claims: map[string]any{"iat": "yesterday"},
+			opts:   go_sd_jwt.BatchIssuanceOptions{HolderKeys: public},
+			err:    "invalid issuance: iat claim must be a numeric date",
+		},
+		{
+			name:   "non numeric exp",
+			claims: map[string]any{"iat": 1700000000, "exp": "tomorrow"},
+			opts:   go_sd_jwt.BatchIssuanceOptions{HolderKeys: public},
+			err:    "invalid issuance: exp claim must be a numeric date",
+		},
+		{
+			name: "salt reused",
+			opts: go_sd_jwt.BatchIssuanceOptions{
+				IssuanceOptions: go_sd_jwt.IssuanceOptions{
+					SelectivelyDisclosable: []string{"given_name"},
+					SaltSource:             constantSaltSource{},
+				},
+				HolderKeys: public,
+			},
+			err: "failed to issue copy 1: error generating salt: salt reused across the batch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Signer = issuerSigner
+			claims := issuanceClaims()
+			for k, v := range tt.claims {
+				claims[k] = v
+			}
+			_, err := go_sd_jwt.IssueBatch(claims, tt.opts)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestIssueBatch_DefaultIatJitter(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	_, public := holderKeys(t, 5)
+
+	claims := issuanceClaims()
+	claims["iat"] = 1700000000
+	copies, err := go_sd_jwt.IssueBatch(claims, go_sd_jwt.BatchIssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+		HolderKeys:      public,
+	})
+	require.NoError(t, err)
+
+	iats := map[int64]bool{}
+	for _, sdJwt := range copies {
+		iat := int64(sdJwt.Body["iat"].(float64))
+		assert.LessOrEqual(t, iat, int64(1700000000))
+		assert.GreaterOrEqual(t, iat, int64(1700000000-go_sd_jwt.DefaultIatJitter/time.Second))
+		iats[iat] = true
+	}
+	assert.Greater(t, len(iats), 1, "copies should not share an iat")
+}
+
+func TestIssueBatch_ProvidedValidityWindow(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	_, public := holderKeys(t, 20)
+
+	// exp is exactly the maximum lifetime after iat, so moving iat back without exp would exceed it
+	claims := issuanceClaims()
+	claims["iat"] = 1700000000
+	claims["nbf"] = 1700000060
+	claims["exp"] = 1700003600
+	copies, err := go_sd_jwt.IssueBatch(claims, go_sd_jwt.BatchIssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:   issuerSigner,
+			Validity: go_sd_jwt.Validity{MaxLifetime: time.Hour},
+		},
+		HolderKeys: public,
+	})
+	require.NoError(t, err)
+
+	for _, sdJwt := range copies {
+		iat := sdJwt.Body["iat"].(float64)
+		assert.LessOrEqual(t, iat, float64(1700000000))
+		assert.Equal(t, iat+60, sdJwt.Body["nbf"])
+		assert.Equal(t, iat+3600, sdJwt.Body["exp"])
+	}
+}
+
+func TestIssueBatch_FractionalIat(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	_, public := holderKeys(t, 3)
+
+	claims := issuanceClaims()
+	// as decoded with json.Decoder.UseNumber, e.g. by IssueFromJwt
+	claims["iat"] = json.Number("1700000000.5")
+	copies, err := go_sd_jwt.IssueBatch(claims, go_sd_jwt.BatchIssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+		HolderKeys:      public,
+	})
+	require.NoError(t, err)
+
+	for _, sdJwt := range copies {
+		iat := sdJwt.Body["iat"].(float64)
+		assert.LessOrEqual(t, iat, 1700000000.5)
+		assert.GreaterOrEqual(t, iat, float64(1700000000-go_sd_jwt.DefaultIatJitter/time.Second))
+	}
+}
+
+func TestIssueBatch_Validity(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	_, public := holderKeys(t, 5)
+
+	copies, err := go_sd_jwt.IssueBatch(issuanceClaims(), go_sd_jwt.BatchIssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer: issuerSigner,
+			Validity: go_sd_jwt.Validity{
+				Clock:       fixedClock(1700000000),
+				Lifetime:    24 * time.Hour,
+				MaxLifetime: 24 * time.Hour,
+				NotBefore:   true,
+			},
+		},
+		HolderKeys: public,
+		IatJitter:  2 * time.Hour,
+	})
+	require.NoError(t, err)
+
+	iats := map[float64]bool{}
+	for _, sdJwt := range copies {
+		iat := sdJwt.Body["iat"].(float64)
+		assert.LessOrEqual(t, iat, float64(1700000000))
+		assert.GreaterOrEqual(t, iat, float64(1700000000-7200))
+		assert.Equal(t, iat, sdJwt.Body["nbf"])
+		assert.Equal(t, iat+86400, sdJwt.Body["exp"])
+		iats[iat] = true
+	}
+	assert.Greater(t, len(iats), 1, "copies should not share an iat")
+}
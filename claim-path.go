@@ -0,0 +1,161 @@
+package go_sd_jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ClaimPath identifies a claim within a set of claims.
+// Each element is either a string selecting an object member, an int selecting an array element or nil selecting
+// every element of an array, in line with the claims path pointers used by SD-JWT VC and OpenID4VP.
+type ClaimPath []any
+
+// ParseClaimPath parses a claim path in dot notation, e.g.
+//   - address.street_address
+//   - nationalities[*]
+//   - verified_claims.verification.evidence[0].document.number
+//
+// Object member names containing reserved characters can be written in bracket notation, e.g. ["a.b"].
+func ParseClaimPath(path string) (ClaimPath, error) {
+	var p ClaimPath
+	i := 0
+	expectName := true
+	for i < len(path) {
+		switch {
+		case strings.HasPrefix(path[i:], "[\""):
+			// a quoted name may itself contain escaped quotes and ']', so the quoted string is scanned honouring escapes
+			quoted, err := strconv.QuotedPrefix(path[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid claim path %s: invalid quoted name", path)
+			}
+			if !strings.HasPrefix(path[i+1+len(quoted):], "]") {
+				return nil, fmt.Errorf("invalid claim path %s: unterminated quoted name", path)
+			}
+			name, err := strconv.Unquote(quoted)
+			if err != nil || name == "" {
+				return nil, fmt.Errorf("invalid claim path %s: invalid quoted name", path)
+			}
+			p = append(p, name)
+			i += len(quoted) + 2
+			expectName = false
+		case path[i] == '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end == -1 {
+				return nil, fmt.Errorf("invalid claim path %s: unterminated '['", path)
+			}
+			selector := path[i+1 : i+end]
+			switch selector {
+			case "*":
+				p = append(p, nil)
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid claim path %s: invalid array index %s", path, selector)
+				}
+				p = append(p, index)
+			}
+			i += end + 1
+			expectName = false
+		case path[i] == '.':
+			if expectName {
+				return nil, fmt.Errorf("invalid claim path %s: empty claim name", path)
+			}
+			expectName = true
+			i++
+		default:
+			if !expectName {
+				return nil, fmt.Errorf("invalid claim path %s: expected '.' or '[' at position %d", path, i)
+			}
+			end := strings.IndexAny(path[i:], ".[")
+			if end == -1 {
+				end = len(path) - i
+			}
+			if end == 0 || strings.ContainsRune(path[i:i+end], ']') {
+				return nil, fmt.Errorf("invalid claim path %s: invalid claim name", path)
+			}
+			p = append(p, path[i:i+end])
+			i += end
+			expectName = false
+		}
+	}
+	if len(p) == 0 || expectName {
+		return nil, fmt.Errorf("invalid claim path %s: empty claim name", path)
+	}
+	return p, nil
+}
+
+// String returns the claim path in the dot notation accepted by ParseClaimPath
+func (p ClaimPath) String() string {
+	var sb strings.Builder
+	for i, element := range p {
+		switch v := element.(type) {
+		case nil:
+			sb.WriteString("[*]")
+		case int:
+			sb.WriteString("[" + strconv.Itoa(v) + "]")
+		case string:
+			if v == "" || strings.ContainsAny(v, ".[]\"") {
+				sb.WriteString("[" + strconv.Quote(v) + "]")
+				continue
+			}
+			if i > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(v)
+		}
+	}
+	return sb.String()
+}
+
+// Matches reports whether the concrete claim path matches p, where a nil element of p matches any array index
+func (p ClaimPath) Matches(path ClaimPath) bool {
+	if len(p) != len(path) {
+		return false
+	}
+	for i, element := range p {
+		if element == nil {
+			if _, ok := path[i].(int); !ok {
+				return false
+			}
+			continue
+		}
+		if element != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Append returns a new claim path with the provided element appended
+func (p ClaimPath) Append(element any) ClaimPath {
+	cp := make(ClaimPath, len(p), len(p)+1)
+	copy(cp, p)
+	return append(cp, element)
+}
+
+// UnmarshalJSON parses a claim path from its JSON array form, e.g. ["nationalities", null]
+func (p *ClaimPath) UnmarshalJSON(b []byte) error {
+	var raw []any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	path := make(ClaimPath, len(raw))
+	for i, element := range raw {
+		switch v := element.(type) {
+		case nil, string:
+			path[i] = v
+		case float64:
+			if v < 0 || v != float64(int(v)) {
+				return fmt.Errorf("invalid claim path element: %v", v)
+			}
+			path[i] = int(v)
+		default:
+			return errors.New("claim path elements must be strings, non-negative integers or null")
+		}
+	}
+	*p = path
+	return nil
+}
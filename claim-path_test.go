@@ -0,0 +1,127 @@
+package go_sd_jwt_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseClaimPath(t *testing.T) {
+	tests := map[string]struct {
+		path     string
+		expected go_sd_jwt.ClaimPath
+		err      string
+	}{
+		"single claim": {
+			path:     "given_name",
+			expected: go_sd_jwt.ClaimPath{"given_name"},
+		},
+		"nested claim": {
+			path:     "address.street_address",
+			expected: go_sd_jwt.ClaimPath{"address", "street_address"},
+		},
+		"every array element": {
+			path:     "nationalities[*]",
+			expected: go_sd_jwt.ClaimPath{"nationalities", nil},
+		},
+		"indexed array element": {
+			path:     "verified_claims.verification.evidence[0].document.number",
+			expected: go_sd_jwt.ClaimPath{"verified_claims", "verification", "evidence", 0, "document", "number"},
+		},
+		"nested arrays": {
+			path:     "matrix[1][*]",
+			expected: go_sd_jwt.ClaimPath{"matrix", 1, nil},
+		},
+		"quoted claim name": {
+			path:     `claims["a.b"].c`,
+			expected: go_sd_jwt.ClaimPath{"claims", "a.b", "c"},
+		},
+		"quoted claim name with escaped quote and bracket": {
+			path:     `claims["a\"]b"].c`,
+			expected: go_sd_jwt.ClaimPath{"claims", "a\"]b", "c"},
+		},
+		"unterminated quoted name": {
+			path: `claims["a"`,
+			err:  `invalid claim path claims["a": unterminated quoted name`,
+		},
+		"empty path": {
+			path: "",
+			err:  "invalid claim path : empty claim name",
+		},
+		"trailing dot": {
+			path: "address.",
+			err:  "invalid claim path address.: empty claim name",
+		},
+		"double dot": {
+			path: "address..street_address",
+			err:  "invalid claim path address..street_address: empty claim name",
+		},
+		"unterminated index": {
+			path: "nationalities[0",
+			err:  "invalid claim path nationalities[0: unterminated '['",
+		},
+		"negative index": {
+			path: "nationalities[-1]",
+			err:  "invalid claim path nationalities[-1]: invalid array index -1",
+		},
+		"missing separator": {
+			path: "nationalities[0]name",
+			err:  "invalid claim path nationalities[0]name: expected '.' or '[' at position 16",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, err := go_sd_jwt.ParseClaimPath(tt.path)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, path)
+			assert.Equal(t, tt.path, path.String())
+		})
+	}
+}
+
+func TestClaimPath_StringRoundTrip(t *testing.T) {
+	paths := []go_sd_jwt.ClaimPath{
+		{"a\"]b"},
+		{"claims", `"]`, 0, nil},
+		{"a.b", "[c]", `d\"e`},
+		{`\`, `"`},
+	}
+	for _, path := range paths {
+		parsed, err := go_sd_jwt.ParseClaimPath(path.String())
+		require.NoError(t, err, path.String())
+		assert.Equal(t, path, parsed)
+	}
+}
+
+func TestClaimPath_UnmarshalJSON(t *testing.T) {
+	var path go_sd_jwt.ClaimPath
+	require.NoError(t, json.Unmarshal([]byte(`["nationalities", null, "code", 2]`), &path))
+	assert.Equal(t, go_sd_jwt.ClaimPath{"nationalities", nil, "code", 2}, path)
+
+	err := json.Unmarshal([]byte(`["nationalities", 1.5]`), &path)
+	require.Error(t, err)
+	assert.Equal(t, "invalid claim path element: 1.5", err.Error())
+
+	err = json.Unmarshal([]byte(`["nationalities", true]`), &path)
+	require.Error(t, err)
+	assert.Equal(t, "claim path elements must be strings, non-negative integers or null", err.Error())
+}
+
+func TestClaimPath_Matches(t *testing.T) {
+	pattern := go_sd_jwt.ClaimPath{"address_history", nil, "street_address"}
+
+	assert.True(t, pattern.Matches(go_sd_jwt.ClaimPath{"address_history", 2, "street_address"}))
+	assert.True(t, go_sd_jwt.ClaimPath{"given_name"}.Matches(go_sd_jwt.ClaimPath{"given_name"}))
+	assert.False(t, pattern.Matches(go_sd_jwt.ClaimPath{"address_history", "2", "street_address"}))
+	assert.False(t, pattern.Matches(go_sd_jwt.ClaimPath{"address_history", 2}))
+	assert.False(t, go_sd_jwt.ClaimPath{"nationalities", 0}.Matches(go_sd_jwt.ClaimPath{"nationalities", 1}))
+}
@@ -0,0 +1,202 @@
+package go_sd_jwt
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/MichaelFraser99/go-jose/jwk"
+)
+
+// Confirmation is the cnf claim binding an SD-JWT to the key of its holder (RFC 7800).
+// At least one confirmation method is used:
+//   - Jwk: the public key of the holder as a JWK
+//   - Kid: an identifier of the holder key, resolved by the verifier through VerificationOptions.HolderKeyResolver
+//   - Jkt: the RFC 7638 JWK SHA-256 thumbprint of the holder key, the key itself is sent in the jwk header of the kb-jwt
+//
+// Jwk and Jkt are mutually exclusive. Kid may accompany either of them as a hint, in which case the holder key is taken
+// from Jwk or Jkt.
+type Confirmation struct {
+	Jwk map[string]any
+	Kid string
+	Jkt string
+}
+
+// HolderKeyResolver returns the public key of the holder identified by the kid of a cnf claim
+type HolderKeyResolver func(kid string) (crypto.PublicKey, error)
+
+// NewJwkConfirmation returns a Confirmation containing the provided holder public key as a JWK
+func NewJwkConfirmation(holderKey crypto.PublicKey) (*Confirmation, error) {
+	holderJwk, err := publicJwk(holderKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Confirmation{Jwk: holderJwk}, nil
+}
+
+// NewJktConfirmation returns a Confirmation containing the JWK thumbprint of the provided holder public key
+func NewJktConfirmation(holderKey crypto.PublicKey) (*Confirmation, error) {
+	thumbprint, err := JwkThumbprint(holderKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Confirmation{Jkt: thumbprint}, nil
+}
+
+// JwkThumbprint returns the base64url encoded RFC 7638 JWK thumbprint of the provided public key using SHA-256
+func JwkThumbprint(publicKey crypto.PublicKey) (string, error) {
+	publicJwk, err := publicJwk(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return jwkThumbprint(publicJwk)
+}
+
+func publicJwk(publicKey crypto.PublicKey) (map[string]any, error) {
+	if publicKey == nil {
+		return nil, errors.New("a holder public key must be provided")
+	}
+	m, err := jwk.PublicJwk(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert holder public key to a jwk: %w", err)
+	}
+	return *m, nil
+}
+
+// jwkThumbprint calculates the thumbprint over the required members of the JWK in lexicographic order
+func jwkThumbprint(publicJwk map[string]any) (string, error) {
+	var required []string
+	switch publicJwk["kty"] {
+	case "EC":
+		required = []string{"crv", "kty", "x", "y"}
+	case "RSA":
+		required = []string{"e", "kty", "n"}
+	case "OKP":
+		required = []string{"crv", "kty", "x"}
+	default:
+		return "", fmt.Errorf("unsupported jwk kty: %v", publicJwk["kty"])
+	}
+
+	members := make(map[string]string, len(required))
+	for _, name := range required {
+		value, ok := publicJwk[name].(string)
+		if !ok || value == "" {
+			return "", fmt.Errorf("jwk is missing the required member %s", name)
+		}
+		members[name] = value
+	}
+
+	// encoding/json orders map keys lexicographically and adds no whitespace, as required by RFC 7638
+	b, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// publicKeyFromJwk converts a JWK to a public key without panicking on a malformed kty
+func publicKeyFromJwk(publicJwk map[string]any) (crypto.PublicKey, error) {
+	if _, ok := publicJwk["kty"].(string); !ok {
+		return nil, errors.New("no kty claim present in jwk")
+	}
+	return jwk.PublicFromJwk(publicJwk)
+}
+
+func (c *Confirmation) validate() error {
+	if c.Jwk == nil && c.Kid == "" && c.Jkt == "" {
+		return errors.New("cnf must contain at least one of jwk, kid or jkt")
+	}
+	if c.Jwk != nil && c.Jkt != "" {
+		return errors.New("cnf must not contain both jwk and jkt")
+	}
+	if c.Jwk != nil {
+		if _, err := publicKeyFromJwk(c.Jwk); err != nil {
+			return fmt.Errorf("invalid cnf jwk: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+func (c *Confirmation) claim() map[string]any {
+	claim := map[string]any{}
+	if c.Jwk != nil {
+		claim["jwk"] = c.Jwk
+	}
+	if c.Kid != "" {
+		claim["kid"] = c.Kid
+	}
+	if c.Jkt != "" {
+		claim["jkt"] = c.Jkt
+	}
+	return claim
+}
+
+// Confirmation returns the cnf claim of the issuer JWT, or nil if not present
+func (s *SdJwt) Confirmation() (*Confirmation, error) {
+	v, ok := s.Body["cnf"]
+	if !ok {
+		return nil, nil
+	}
+	cnf, ok := v.(map[string]any)
+	if !ok {
+		return nil, errors.New("'cnf' claim is not an object")
+	}
+
+	c := &Confirmation{}
+	if v, ok := cnf["jwk"]; ok {
+		if c.Jwk, ok = v.(map[string]any); !ok {
+			return nil, errors.New("'jwk' in 'cnf' claim is not an object")
+		}
+	}
+	if v, ok := cnf["kid"]; ok {
+		if c.Kid, ok = v.(string); !ok {
+			return nil, errors.New("'kid' in 'cnf' claim is not a string")
+		}
+	}
+	if v, ok := cnf["jkt"]; ok {
+		if c.Jkt, ok = v.(string); !ok {
+			return nil, errors.New("'jkt' in 'cnf' claim is not a string")
+		}
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// resolveHolderKey returns the holder public key identified by the confirmation. kbHeaderJwk is the jwk header of the
+// kb-jwt, which is required for and checked against a jkt confirmation.
+func (c *Confirmation) resolveHolderKey(resolver HolderKeyResolver, kbHeaderJwk map[string]any) (crypto.PublicKey, error) {
+	switch {
+	case c.Jwk != nil:
+		return publicKeyFromJwk(c.Jwk)
+	case c.Jkt == "":
+		if resolver == nil {
+			return nil, errors.New("a HolderKeyResolver is required to resolve the 'kid' in the 'cnf' claim")
+		}
+		key, err := resolver(c.Kid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve holder key %s: %w", c.Kid, err)
+		}
+		if key == nil {
+			return nil, fmt.Errorf("no holder key found for %s", c.Kid)
+		}
+		return key, nil
+	default:
+		if kbHeaderJwk == nil {
+			return nil, errors.New("the kb-jwt header must contain a jwk when the 'cnf' claim contains a jkt")
+		}
+		thumbprint, err := jwkThumbprint(kbHeaderJwk)
+		if err != nil {
+			return nil, err
+		}
+		if thumbprint != c.Jkt {
+			return nil, errors.New("the kb-jwt header jwk does not match the jkt in the 'cnf' claim")
+		}
+		return publicKeyFromJwk(kbHeaderJwk)
+	}
+}
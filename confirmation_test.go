@@ -0,0 +1,246 @@
+package go_sd_jwt_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJwkThumbprint(t *testing.T) {
+	// example from RFC 7638 section 3.1
+	n, err := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	require.NoError(t, err)
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}
+
+	thumbprint, err := go_sd_jwt.JwkThumbprint(key)
+	require.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+
+	_, err = go_sd_jwt.JwkThumbprint(nil)
+	require.Error(t, err)
+	assert.Equal(t, "a holder public key must be provided", err.Error())
+}
+
+// issueWithConfirmation issues an SD-JWT bound to a holder key through the provided confirmation
+func issueWithConfirmation(t *testing.T, cnf *go_sd_jwt.Confirmation) (*go_sd_jwt.SdJwt, crypto.PublicKey) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	sdJwt, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+		Signer:                 issuerSigner,
+		SelectivelyDisclosable: []string{"given_name"},
+		Cnf:                    cnf,
+	})
+	require.NoError(t, err)
+	return sdJwt, issuerSigner.Public()
+}
+
+var (
+	keyBindingOptions         = go_sd_jwt.KeyBindingOptions{Alg: "ES256", Aud: "https://verifier.example.com", Nonce: "nonce-1"}
+	verifiedKeyBindingOptions = go_sd_jwt.KeyBindingOptions{Alg: "ES256", Aud: "https://verifier.example.com", Nonce: "nonce-1", VerifyHolderKey: true}
+)
+
+// present adds a key binding jwt signed by the holder key and parses the resulting presentation
+func present(t *testing.T, sdJwt *go_sd_jwt.SdJwt, holderKey *ecdsa.PrivateKey) *go_sd_jwt.SdJwt {
+	require.NoError(t, sdJwt.SignKeyBindingJwt(holderKey, keyBindingOptions))
+	token, err := sdJwt.Token()
+	require.NoError(t, err)
+	presented, err := go_sd_jwt.New(*token)
+	require.NoError(t, err)
+	return presented
+}
+
+func TestConfirmation_Jwk(t *testing.T) {
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cnf, err := go_sd_jwt.NewJwkConfirmation(&holderKey.PublicKey)
+	require.NoError(t, err)
+
+	sdJwt, issuerKey := issueWithConfirmation(t, cnf)
+	parsed, err := sdJwt.Confirmation()
+	require.NoError(t, err)
+	assert.Equal(t, "EC", parsed.Jwk["kty"])
+	assert.Empty(t, parsed.Kid)
+	assert.Empty(t, parsed.Jkt)
+
+	presented := present(t, sdJwt, holderKey)
+	require.NoError(t, presented.Verify(go_sd_jwt.VerificationOptions{IssuerKey: issuerKey, VerifyKBJwtSignature: true}))
+}
+
+func TestConfirmation_Kid(t *testing.T) {
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	sdJwt, issuerKey := issueWithConfirmation(t, &go_sd_jwt.Confirmation{Kid: "holder-key-1"})
+	parsed, err := sdJwt.Confirmation()
+	require.NoError(t, err)
+	assert.Equal(t, &go_sd_jwt.Confirmation{Kid: "holder-key-1"}, parsed)
+
+	presented := present(t, sdJwt, holderKey)
+
+	resolver := func(kid string) (crypto.PublicKey, error) {
+		if kid != "holder-key-1" {
+			return nil, errors.New("unknown key")
+		}
+		return &holderKey.PublicKey, nil
+	}
+	require.NoError(t, presented.Verify(go_sd_jwt.VerificationOptions{
+		IssuerKey:            issuerKey,
+		VerifyKBJwtSignature: true,
+		HolderKeyResolver:    resolver,
+	}))
+
+	err = presented.Verify(go_sd_jwt.VerificationOptions{VerifyKBJwtSignature: true})
+	require.Error(t, err)
+	assert.Equal(t, "invalid token: failed to determine holder public key: a HolderKeyResolver is required to resolve the 'kid' in the 'cnf' claim", err.Error())
+
+	err = presented.Verify(go_sd_jwt.VerificationOptions{
+		VerifyKBJwtSignature: true,
+		HolderKeyResolver: func(string) (crypto.PublicKey, error) {
+			return nil, errors.New("unknown key")
+		},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "invalid token: failed to determine holder public key: failed to resolve holder key holder-key-1: unknown key", err.Error())
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	err = presented.Verify(go_sd_jwt.VerificationOptions{
+		VerifyKBJwtSignature: true,
+		HolderKeyResolver: func(string) (crypto.PublicKey, error) {
+			return &otherKey.PublicKey, nil
+		},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "invalid token: kb-jwt signature verification failed", err.Error())
+}
+
+func TestConfirmation_Jkt(t *testing.T) {
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cnf, err := go_sd_jwt.NewJktConfirmation(&holderKey.PublicKey)
+	require.NoError(t, err)
+
+	sdJwt, issuerKey := issueWithConfirmation(t, cnf)
+	assert.Equal(t, map[string]any{"jkt": cnf.Jkt}, sdJwt.Body["cnf"])
+
+	presented := present(t, sdJwt, holderKey)
+	require.NoError(t, presented.Verify(go_sd_jwt.VerificationOptions{IssuerKey: issuerKey, VerifyKBJwtSignature: true}))
+
+	// a kb-jwt carrying a key other than the one in the thumbprint must be rejected
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	other, _ := issueWithConfirmation(t, cnf)
+	err = other.SignKeyBindingJwt(otherKey, verifiedKeyBindingOptions)
+	require.Error(t, err)
+	assert.Equal(t, "the holder key does not match the jkt in the 'cnf' claim", err.Error())
+
+	_, err = other.PrepareKeyBindingJwt(go_sd_jwt.KeyBindingOptions{Alg: "ES256", Aud: "https://verifier.example.com", Nonce: "nonce-1", VerifyHolderKey: true})
+	require.Error(t, err)
+	assert.Equal(t, "a holder key must be provided when the 'cnf' claim contains a jkt", err.Error())
+}
+
+func TestConfirmation_Errors(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cnf, err := go_sd_jwt.NewJwkConfirmation(&holderKey.PublicKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+		cnf    *go_sd_jwt.Confirmation
+		err    string
+	}{
+		{
+			name:   "no confirmation method",
+			claims: issuanceClaims(),
+			cnf:    &go_sd_jwt.Confirmation{},
+			err:    "invalid issuance: cnf must contain at least one of jwk, kid or jkt",
+		},
+		{
+			name:   "jwk and jkt",
+			claims: issuanceClaims(),
+			cnf:    &go_sd_jwt.Confirmation{Jwk: cnf.Jwk, Jkt: "thumbprint"},
+			err:    "invalid issuance: cnf must not contain both jwk and jkt",
+		},
+		{
+			name:   "invalid jwk",
+			claims: issuanceClaims(),
+			cnf:    &go_sd_jwt.Confirmation{Jwk: map[string]any{"kty": 1}},
+			err:    "invalid issuance: invalid cnf jwk: no kty claim present in jwk",
+		},
+		{
+			name: "cnf already in claims",
+			claims: func() map[string]any {
+				claims := issuanceClaims()
+				claims["cnf"] = map[string]any{"kid": "holder-key-1"}
+				return claims
+			}(),
+			cnf: cnf,
+			err: "invalid issuance: claims must not contain a cnf claim when Cnf is set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := go_sd_jwt.Issue(tt.claims, go_sd_jwt.IssuanceOptions{Signer: issuerSigner, Cnf: tt.cnf})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+
+	t.Run("holder key does not match jwk", func(t *testing.T) {
+		sdJwt, _ := issueWithConfirmation(t, cnf)
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		err = sdJwt.SignKeyBindingJwt(otherKey, verifiedKeyBindingOptions)
+		require.Error(t, err)
+		assert.Equal(t, "the holder key does not match the jwk in the 'cnf' claim", err.Error())
+
+		// without VerifyHolderKey the holder key is not checked against the cnf claim
+		sdJwt, _ = issueWithConfirmation(t, cnf)
+		require.NoError(t, sdJwt.SignKeyBindingJwt(otherKey, keyBindingOptions))
+	})
+
+	t.Run("no cnf claim", func(t *testing.T) {
+		sdJwt, _ := issueWithConfirmation(t, nil)
+		err := sdJwt.SignKeyBindingJwt(holderKey, verifiedKeyBindingOptions)
+		require.Error(t, err)
+		assert.Equal(t, "the sd-jwt has no 'cnf' claim to verify the holder key against", err.Error())
+
+		sdJwt, _ = issueWithConfirmation(t, nil)
+		require.NoError(t, sdJwt.SignKeyBindingJwt(holderKey, keyBindingOptions))
+	})
+}
+
+func TestConfirmation_KidWithJwk(t *testing.T) {
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cnf, err := go_sd_jwt.NewJwkConfirmation(&holderKey.PublicKey)
+	require.NoError(t, err)
+	cnf.Kid = "holder-key-1"
+
+	sdJwt, issuerKey := issueWithConfirmation(t, cnf)
+	parsed, err := sdJwt.Confirmation()
+	require.NoError(t, err)
+	assert.Equal(t, "holder-key-1", parsed.Kid)
+	assert.Equal(t, "EC", parsed.Jwk["kty"])
+
+	// the key is taken from the jwk, so no HolderKeyResolver is needed
+	presented := present(t, sdJwt, holderKey)
+	require.NoError(t, presented.Verify(go_sd_jwt.VerificationOptions{IssuerKey: issuerKey, VerifyKBJwtSignature: true}))
+}
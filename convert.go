@@ -0,0 +1,90 @@
+package go_sd_jwt
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+)
+
+// ConversionOptions configures how IssueFromJwt converts a JWT into an SD-JWT.
+// The embedded IssuanceOptions are passed to Issue, so Signer is the key of the new issuer and SelectivelyDisclosable
+// is the disclosure policy applied to the claims of the JWT.
+type ConversionOptions struct {
+	IssuanceOptions
+	// VerificationKey, when set, is used to verify the signature of the JWT before it is converted
+	VerificationKey crypto.PublicKey
+	// ValidateExpiry rejects a JWT whose exp claim has passed or whose nbf claim has not yet been reached
+	ValidateExpiry bool
+	// ClaimsOverride replaces claims of the JWT, e.g. iss when the SD-JWT is issued under a new issuer identifier or exp
+	// to shorten its lifetime. A claim with a nil value is removed.
+	ClaimsOverride map[string]any
+}
+
+// IssueFromJwt converts a signed JWT into an SD-JWT signed by a new issuer, so selective disclosure can be introduced
+// without changing the issuer of the original JWT.
+// All claims of the JWT, including iss, iat and exp, are carried over unless replaced through opts.ClaimsOverride. The
+// header of the JWT is not carried over, the header of the SD-JWT is built from opts as for Issue, so a JWT with a crit
+// header is rejected as its critical extensions would be lost. Numeric claims are carried over without loss of precision.
+func IssueFromJwt(token string, opts ConversionOptions) (*SdJwt, error) {
+	if strings.Contains(token, "~") {
+		return nil, fmt.Errorf("%wtoken must be a JWT, not an SD-JWT", e.ErrInvalidToken)
+	}
+	sections := strings.Split(token, ".")
+	if len(sections) != 3 {
+		return nil, fmt.Errorf("%wnot a valid JWT", e.ErrInvalidToken)
+	}
+
+	jwt := &SdJwt{rawHead: sections[0], rawPayload: sections[1], Signature: sections[2]}
+	hb, err := base64.RawURLEncoding.DecodeString(sections[0])
+	if err != nil {
+		return nil, fmt.Errorf("%wfailed to decode header: %s", e.ErrInvalidToken, err.Error())
+	}
+	if err := json.Unmarshal(hb, &jwt.Head); err != nil {
+		return nil, fmt.Errorf("%wfailed to json parse decoded header: %s", e.ErrInvalidToken, err.Error())
+	}
+	if _, ok := jwt.Head["crit"]; ok {
+		return nil, fmt.Errorf("%wJWT must not contain a crit header, its critical extensions cannot be carried over", e.ErrInvalidToken)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(sections[1])
+	if err != nil {
+		return nil, fmt.Errorf("%wfailed to decode payload: %s", e.ErrInvalidToken, err.Error())
+	}
+	if err := json.Unmarshal(b, &jwt.Body); err != nil {
+		return nil, fmt.Errorf("%wfailed to json parse decoded payload: %s", e.ErrInvalidToken, err.Error())
+	}
+	// the claims are decoded again with json.Number values so integers beyond 2^53 are issued unchanged
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var claims map[string]any
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, fmt.Errorf("%wfailed to json parse decoded payload: %s", e.ErrInvalidToken, err.Error())
+	}
+
+	if opts.VerificationKey != nil {
+		if err := jwt.verifyIssuerSignature(opts.VerificationKey); err != nil {
+			return nil, err
+		}
+	}
+	if opts.ValidateExpiry {
+		if err := jwt.validateExpiry(); err != nil {
+			return nil, err
+		}
+		if err := jwt.validateNotBefore(); err != nil {
+			return nil, err
+		}
+	}
+
+	for k, v := range opts.ClaimsOverride {
+		if v == nil {
+			delete(claims, k)
+		} else {
+			claims[k] = v
+		}
+	}
+	return Issue(claims, opts.IssuanceOptions)
+}
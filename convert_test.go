@@ -0,0 +1,194 @@
+package go_sd_jwt_test
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signJwt(t *testing.T, claims map[string]any, signer model.Signer) string {
+	return signJwtWithHeader(t, map[string]any{"alg": signer.Alg().String(), "typ": "JWT"}, claims, signer)
+}
+
+func signJwtWithHeader(t *testing.T, header map[string]any, claims map[string]any, signer model.Signer) string {
+	hb, err := json.Marshal(header)
+	require.NoError(t, err)
+	bb, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(hb) + "." + base64.RawURLEncoding.EncodeToString(bb)
+	sig, err := signer.Sign(rand.Reader, []byte(signingInput), nil)
+	require.NoError(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestIssueFromJwt(t *testing.T) {
+	legacySigner, err := jws.GetSigner(model.RS256, nil)
+	require.NoError(t, err)
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := issuanceClaims()
+	claims["iat"] = 1700000000
+	claims["exp"] = 4000000000
+	token := signJwt(t, claims, legacySigner)
+
+	sdJwt, err := go_sd_jwt.IssueFromJwt(token, go_sd_jwt.ConversionOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			Typ:                    "example+sd-jwt",
+			SelectivelyDisclosable: []string{"given_name", "family_name", "address", "nationalities[*]"},
+		},
+		VerificationKey: legacySigner.Public(),
+		ValidateExpiry:  true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: issuerSigner.Public()}))
+	assert.Equal(t, "ES256", sdJwt.Head["alg"])
+	assert.Equal(t, "example+sd-jwt", sdJwt.Head["typ"])
+	assert.Equal(t, "https://issuer.example.com", sdJwt.Body["iss"])
+	assert.Equal(t, float64(1700000000), sdJwt.Body["iat"])
+	assert.Equal(t, float64(4000000000), sdJwt.Body["exp"])
+	assert.NotContains(t, sdJwt.Body, "given_name")
+	assert.Len(t, sdJwt.Disclosures, 5)
+
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	require.NoError(t, err)
+	assert.Equal(t, roundTrip(t, claims), disclosed)
+}
+
+func TestIssueFromJwt_ClaimsOverride(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := issuanceClaims()
+	claims["iat"] = 1700000000
+	claims["exp"] = 4000000000
+	token := signJwt(t, claims, issuerSigner)
+
+	sdJwt, err := go_sd_jwt.IssueFromJwt(token, go_sd_jwt.ConversionOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+		ClaimsOverride: map[string]any{
+			"iss": "https://sd-issuer.example.com",
+			"exp": 1800000000,
+			"sub": nil,
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://sd-issuer.example.com", sdJwt.Body["iss"])
+	assert.Equal(t, float64(1700000000), sdJwt.Body["iat"])
+	assert.Equal(t, float64(1800000000), sdJwt.Body["exp"])
+	assert.NotContains(t, sdJwt.Body, "sub")
+}
+
+func TestIssueFromJwt_LargeIntegers(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	// 9007199254740993 is 2^53 + 1, which cannot be represented as a float64
+	hb, err := json.Marshal(map[string]any{"alg": "ES256", "typ": "JWT"})
+	require.NoError(t, err)
+	payload := `{"iss":"https://issuer.example.com","account":9007199254740993,"balance":{"cents":9007199254740993}}`
+	signingInput := base64.RawURLEncoding.EncodeToString(hb) + "." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	sig, err := issuerSigner.Sign(rand.Reader, []byte(signingInput), nil)
+	require.NoError(t, err)
+	token := signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	sdJwt, err := go_sd_jwt.IssueFromJwt(token, go_sd_jwt.ConversionOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			SelectivelyDisclosable: []string{"account", "balance.cents"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sdJwt.Disclosures, 2)
+	for _, d := range sdJwt.Disclosures {
+		value, err := base64.RawURLEncoding.DecodeString(d.EncodedValue)
+		require.NoError(t, err)
+		assert.Contains(t, string(value), `,9007199254740993]`)
+	}
+}
+
+func TestIssueFromJwt_Errors(t *testing.T) {
+	legacySigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	otherSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := issuanceClaims()
+	token := signJwt(t, claims, legacySigner)
+	crit := signJwtWithHeader(t, map[string]any{"alg": "ES256", "typ": "JWT", "crit": []string{"ext"}, "ext": true}, claims, legacySigner)
+	claims["nbf"] = 4000000000
+	notYetValid := signJwt(t, claims, legacySigner)
+	delete(claims, "nbf")
+	claims["exp"] = 1000000000
+	expired := signJwt(t, claims, legacySigner)
+	claims["_sd_alg"] = "sha-256"
+	reserved := signJwt(t, claims, legacySigner)
+
+	tests := []struct {
+		name  string
+		token string
+		opts  go_sd_jwt.ConversionOptions
+		err   string
+	}{
+		{
+			name:  "sd-jwt",
+			token: token + "~",
+			err:   "invalid token: token must be a JWT, not an SD-JWT",
+		},
+		{
+			name:  "malformed",
+			token: "not.a-jwt",
+			err:   "invalid token: not a valid JWT",
+		},
+		{
+			name:  "invalid signature",
+			token: token,
+			opts:  go_sd_jwt.ConversionOptions{VerificationKey: otherSigner.Public()},
+			err:   "invalid token: signature verification failed",
+		},
+		{
+			name:  "expired",
+			token: expired,
+			opts:  go_sd_jwt.ConversionOptions{ValidateExpiry: true},
+			err:   "invalid token: token has expired",
+		},
+		{
+			name:  "not yet valid",
+			token: notYetValid,
+			opts:  go_sd_jwt.ConversionOptions{ValidateExpiry: true},
+			err:   "invalid token: token is not yet valid",
+		},
+		{
+			name:  "crit header",
+			token: crit,
+			err:   "invalid token: JWT must not contain a crit header, its critical extensions cannot be carried over",
+		},
+		{
+			name:  "reserved claim",
+			token: reserved,
+			err:   "invalid issuance: claims must not contain the reserved claim name _sd_alg",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Signer = issuerSigner
+			_, err := go_sd_jwt.IssueFromJwt(tt.token, tt.opts)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package go_sd_jwt
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+)
+
+// DecoyDigests configures the number of decoy digests added during issuance to every _sd array, and as {"...": digest}
+// entries to every array containing selectively disclosable elements.
+// Decoys prevent a verifier from determining how many claims were withheld by the holder.
+// If Max is greater than Min, a random number of decoys between Min and Max (inclusive) is chosen for each array,
+// otherwise exactly Min decoys are added.
+type DecoyDigests struct {
+	Min int
+	Max int
+}
+
+func (d DecoyDigests) validate() error {
+	if d.Min < 0 || d.Max < 0 {
+		return fmt.Errorf("%wthe number of decoy digests must not be negative", e.ErrInvalidIssuance)
+	}
+	if d.Max != 0 && d.Max < d.Min {
+		return fmt.Errorf("%wthe maximum number of decoy digests must not be less than the minimum", e.ErrInvalidIssuance)
+	}
+	return nil
+}
+
+// decoyCount returns the number of decoy digests to add to a single array
+func (b *issuanceBuilder) decoyCount() (int, error) {
+	if b.decoys.Max <= b.decoys.Min {
+		return b.decoys.Min, nil
+	}
+	n, err := b.randomInt(b.decoys.Max - b.decoys.Min + 1)
+	if err != nil {
+		return 0, err
+	}
+	return b.decoys.Min + n, nil
+}
+
+// decoyDigest creates a digest over a newly generated salt, so it cannot match any disclosure
+func (b *issuanceBuilder) decoyDigest() (string, error) {
+	salt, err := b.salts.NewSalt()
+	if err != nil {
+		return "", err
+	}
+	h, err := GetHash(b.sdAlg)
+	if err != nil {
+		return "", err
+	}
+	return hashString(h, salt), nil
+}
+
+// decoyDigests creates the decoy digests for a single array
+func (b *issuanceBuilder) decoyDigests() ([]string, error) {
+	n, err := b.decoyCount()
+	if err != nil {
+		return nil, fmt.Errorf("error generating decoy digests: %w", err)
+	}
+
+	digests := make([]string, n)
+	for i := range digests {
+		digests[i], err = b.decoyDigest()
+		if err != nil {
+			return nil, fmt.Errorf("error generating decoy digests: %w", err)
+		}
+	}
+	return digests, nil
+}
+
+// insertArrayDecoys inserts decoy {"...": digest} entries at random positions in the provided array, preserving the
+// order of the existing elements
+func (b *issuanceBuilder) insertArrayDecoys(arr []any) ([]any, error) {
+	digests, err := b.decoyDigests()
+	if err != nil {
+		return nil, err
+	}
+	for _, digest := range digests {
+		position, err := b.randomInt(len(arr) + 1)
+		if err != nil {
+			return nil, fmt.Errorf("error generating decoy digests: %w", err)
+		}
+		arr = append(arr[:position], append([]any{map[string]any{"...": digest}}, arr[position:]...)...)
+	}
+	return arr, nil
+}
+
+// randomInt returns a value in [0, n) derived from a newly generated salt, so that the number and position of decoys
+// are reproducible when a deterministic SaltSource is used and unpredictable otherwise
+func (b *issuanceBuilder) randomInt(n int) (int, error) {
+	salt, err := b.salts.NewSalt()
+	if err != nil {
+		return 0, err
+	}
+	sum := sha256.Sum256([]byte(salt))
+	return int(binary.BigEndian.Uint64(sum[:8]) % uint64(n)), nil
+}
@@ -0,0 +1,103 @@
+package go_sd_jwt_test
+
+import (
+	"testing"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssue_DecoyDigests(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := issuanceClaims()
+	sdJwt, err := go_sd_jwt.Issue(claims, go_sd_jwt.IssuanceOptions{
+		Signer:                 signer,
+		SdAlg:                  "sha-384",
+		SelectivelyDisclosable: []string{"given_name", "address.locality", "nationalities[0]"},
+		DecoyDigests:           go_sd_jwt.DecoyDigests{Min: 3},
+	})
+	require.NoError(t, err)
+	assert.Len(t, sdJwt.Disclosures, 3)
+
+	assert.Len(t, sdJwt.Body["_sd"], 4)
+	assert.Len(t, sdJwt.Body["address"].(map[string]any)["_sd"], 4)
+
+	nationalities := sdJwt.Body["nationalities"].([]any)
+	require.Len(t, nationalities, 5)
+	assert.Contains(t, nationalities, "DE", "plaintext elements should be retained")
+	for _, n := range nationalities {
+		if m, ok := n.(map[string]any); ok {
+			assert.Len(t, m["..."], 64, "decoys should use the _sd_alg hash")
+		}
+	}
+
+	require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: signer.Public()}))
+
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	require.NoError(t, err)
+	assert.Equal(t, roundTrip(t, claims), disclosed)
+
+	t.Run("decoys remain hidden when disclosures are withheld", func(t *testing.T) {
+		token, err := sdJwt.Token()
+		require.NoError(t, err)
+		presented, err := go_sd_jwt.New(*token)
+		require.NoError(t, err)
+		presented.Disclosures = nil
+
+		disclosed, err := presented.GetDisclosedClaims()
+		require.NoError(t, err)
+		assert.NotContains(t, disclosed, "given_name")
+		assert.Equal(t, []any{"DE"}, disclosed["nationalities"])
+		assert.NotContains(t, disclosed["address"], "locality")
+	})
+}
+
+func TestIssue_DecoyDigestRange(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		sdJwt, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+			Signer:                 signer,
+			SelectivelyDisclosable: []string{"given_name"},
+			DecoyDigests:           go_sd_jwt.DecoyDigests{Min: 1, Max: 4},
+		})
+		require.NoError(t, err)
+
+		n := len(sdJwt.Body["_sd"].([]any))
+		assert.GreaterOrEqual(t, n, 2)
+		assert.LessOrEqual(t, n, 5)
+	}
+}
+
+func TestIssue_DecoyDigestsNotAddedWithoutDigests(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	sdJwt, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+		Signer:                 signer,
+		SelectivelyDisclosable: []string{"address.locality"},
+		DecoyDigests:           go_sd_jwt.DecoyDigests{Min: 2},
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, sdJwt.Body, "_sd")
+	assert.Equal(t, []any{"US", "DE"}, sdJwt.Body["nationalities"])
+}
+
+func TestIssue_DecoyDigestErrors(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	_, err = go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{Signer: signer, DecoyDigests: go_sd_jwt.DecoyDigests{Min: -1}})
+	require.Error(t, err)
+	assert.Equal(t, "invalid issuance: the number of decoy digests must not be negative", err.Error())
+
+	_, err = go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{Signer: signer, DecoyDigests: go_sd_jwt.DecoyDigests{Min: 3, Max: 2}})
+	require.Error(t, err)
+	assert.Equal(t, "invalid issuance: the maximum number of decoy digests must not be less than the minimum", err.Error())
+}
@@ -0,0 +1,190 @@
+package go_sd_jwt
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/MichaelFraser99/go-sd-jwt/v2/disclosure"
+)
+
+// DisclosureNode represents a single disclosure within the structure of an SD-JWT.
+// Path is the claim path of the disclosed claim or array element. Array indexes refer to positions within the
+// issuer-signed payload (or the disclosure containing the array), including any undisclosed or decoy entries.
+// Parent is the disclosure whose value contains the digest of this disclosure, or nil if the digest is part of the
+// issuer-signed payload. Children are the disclosures whose digests are contained in the value of this disclosure.
+type DisclosureNode struct {
+	Disclosure disclosure.Disclosure
+	Digest     string
+	Path       ClaimPath
+	Parent     *DisclosureNode
+	Children   []*DisclosureNode
+
+	// claimPath is Path with array indexes counting only the elements of the issued claims, i.e. skipping digests which
+	// do not match a disclosure such as decoys
+	claimPath ClaimPath
+}
+
+// DisclosureTree returns the disclosures of the SD-JWT arranged by the structure of the claims.
+// The returned nodes are the disclosures referenced directly from the issuer-signed payload, disclosures referenced
+// from within the value of another disclosure (recursive disclosures) are returned as children of that disclosure.
+// Disclosures which are not referenced by any digest are not included.
+func (s *SdJwt) DisclosureTree() ([]*DisclosureNode, error) {
+	strAlg, _ := s.Body["_sd_alg"].(string)
+	if _, err := GetHash(strAlg); err != nil {
+		return nil, err
+	}
+
+	byDigest := make(map[string]disclosure.Disclosure, len(s.Disclosures))
+	for _, d := range s.Disclosures {
+		h, _ := GetHash(strAlg)
+		byDigest[string(d.Hash(h))] = d
+	}
+
+	w := &treeWalker{byDigest: byDigest, seen: map[string]bool{}}
+	if err := w.walk(s.Body, nil, nil, nil); err != nil {
+		return nil, err
+	}
+	return w.roots, nil
+}
+
+// SelectDisclosures returns the disclosures a holder must present to reveal the claims with the provided claim paths
+// (see ParseClaimPath). The disclosures of any parent claims are included, so that recursively disclosed claims can be
+// verified, and the result is ordered parent first. A [*] element in a path matches every element of an array.
+// Array indexes refer to positions within the issued claims, as for IssuanceOptions.SelectivelyDisclosable, so decoy
+// digests are not counted. As a digest whose disclosure has been removed cannot be told apart from a decoy, indexes
+// are only reliable while the SD-JWT holds every disclosure of the array.
+func (s *SdJwt) SelectDisclosures(paths ...string) ([]disclosure.Disclosure, error) {
+	tree, err := s.DisclosureTree()
+	if err != nil {
+		return nil, err
+	}
+
+	var all []*DisclosureNode
+	var flatten func(nodes []*DisclosureNode)
+	flatten = func(nodes []*DisclosureNode) {
+		for _, n := range nodes {
+			all = append(all, n)
+			flatten(n.Children)
+		}
+	}
+	flatten(tree)
+
+	selected := map[*DisclosureNode]bool{}
+	for _, path := range paths {
+		claimPath, err := ParseClaimPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for _, n := range all {
+			if claimPath.Matches(n.claimPath) {
+				found = true
+				for current := n; current != nil; current = current.Parent {
+					selected[current] = true
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no disclosure found for claim %s", path)
+		}
+	}
+
+	var result []disclosure.Disclosure
+	for _, n := range all {
+		if selected[n] {
+			result = append(result, n.Disclosure)
+		}
+	}
+	return result, nil
+}
+
+type treeWalker struct {
+	byDigest map[string]disclosure.Disclosure
+	seen     map[string]bool
+	roots    []*DisclosureNode
+}
+
+func (w *treeWalker) add(digest string, path, claimPath ClaimPath, parent *DisclosureNode, arrayElement bool) error {
+	d, ok := w.byDigest[digest]
+	if !ok {
+		// either a decoy or a disclosure withheld by the holder
+		return nil
+	}
+	if w.seen[digest] {
+		return fmt.Errorf("digest %s is referenced more than once", digest)
+	}
+	w.seen[digest] = true
+
+	if arrayElement && d.Key != nil {
+		return fmt.Errorf("invalid disclosure format for array element %s", path)
+	}
+	if !arrayElement {
+		if d.Key == nil {
+			return errors.New("invalid disclosure format for _sd claim")
+		}
+		path = path.Append(*d.Key)
+		claimPath = claimPath.Append(*d.Key)
+	}
+	node := &DisclosureNode{Disclosure: d, Digest: digest, Path: path, Parent: parent, claimPath: claimPath}
+	if parent == nil {
+		w.roots = append(w.roots, node)
+	} else {
+		parent.Children = append(parent.Children, node)
+	}
+	return w.walk(d.Value, path, claimPath, node)
+}
+
+func (w *treeWalker) walk(v any, path, claimPath ClaimPath, parent *DisclosureNode) error {
+	switch value := v.(type) {
+	case map[string]any:
+		if sd, ok := value["_sd"].([]any); ok {
+			for _, digest := range sd {
+				strDigest, ok := digest.(string)
+				if !ok {
+					return errors.New("malformed _sd claim")
+				}
+				if err := w.add(strDigest, path, claimPath, parent, false); err != nil {
+					return err
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			if k == "_sd" || k == "_sd_alg" {
+				continue
+			}
+			if err := w.walk(value[k], path.Append(k), claimPath.Append(k), parent); err != nil {
+				return err
+			}
+		}
+	case []any:
+		// issued counts the elements of the issued claims, which excludes decoy digests
+		issued := 0
+		for i, element := range value {
+			if m, ok := element.(map[string]any); ok && len(m) == 1 {
+				if digest, ok := m["..."].(string); ok {
+					if _, ok := w.byDigest[digest]; !ok {
+						continue
+					}
+					if err := w.add(digest, path.Append(i), claimPath.Append(issued), parent, true); err != nil {
+						return err
+					}
+					issued++
+					continue
+				}
+			}
+			if err := w.walk(element, path.Append(i), claimPath.Append(issued), parent); err != nil {
+				return err
+			}
+			issued++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package go_sd_jwt_test
+
+import (
+	"testing"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func issueRecursive(t *testing.T) *go_sd_jwt.SdJwt {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	sdJwt, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+		Signer: signer,
+		SelectivelyDisclosable: []string{
+			"address",
+			"address.street_address",
+			"address.locality",
+			"nationalities",
+			"nationalities[*]",
+			"sub",
+		},
+		DecoyDigests: go_sd_jwt.DecoyDigests{Min: 1},
+	})
+	require.NoError(t, err)
+	return sdJwt
+}
+
+func TestIssue_RecursiveDisclosures(t *testing.T) {
+	sdJwt := issueRecursive(t)
+	assert.Len(t, sdJwt.Disclosures, 7)
+	assert.NotContains(t, sdJwt.Body, "address")
+	assert.NotContains(t, sdJwt.Body, "nationalities")
+
+	var address map[string]any
+	for _, d := range sdJwt.Disclosures {
+		if d.Key != nil && *d.Key == "address" {
+			address = d.Value.(map[string]any)
+		}
+	}
+	require.NotNil(t, address, "address disclosure should exist")
+	assert.Equal(t, "US", address["country"])
+	assert.Len(t, address["_sd"], 3, "two digests and one decoy")
+
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	require.NoError(t, err)
+	assert.Equal(t, roundTrip(t, issuanceClaims()), disclosed)
+}
+
+func TestSdJwt_DisclosureTree(t *testing.T) {
+	sdJwt := issueRecursive(t)
+
+	tree, err := sdJwt.DisclosureTree()
+	require.NoError(t, err)
+	require.Len(t, tree, 3)
+
+	byPath := map[string]*go_sd_jwt.DisclosureNode{}
+	for _, root := range tree {
+		assert.Nil(t, root.Parent)
+		byPath[root.Path.String()] = root
+	}
+	require.Contains(t, byPath, "address")
+	require.Contains(t, byPath, "nationalities")
+	require.Contains(t, byPath, "sub")
+
+	address := byPath["address"]
+	require.Len(t, address.Children, 2)
+	for _, child := range address.Children {
+		assert.Same(t, address, child.Parent)
+		assert.Contains(t, []string{"address.street_address", "address.locality"}, child.Path.String())
+		assert.Empty(t, child.Children)
+	}
+
+	nationalities := byPath["nationalities"]
+	require.Len(t, nationalities.Children, 2)
+	for _, child := range nationalities.Children {
+		assert.Same(t, nationalities, child.Parent)
+		assert.Nil(t, child.Disclosure.Key)
+		assert.Equal(t, "nationalities", child.Path[0])
+		assert.IsType(t, 0, child.Path[1])
+	}
+
+	assert.Empty(t, byPath["sub"].Children)
+	assert.NotEmpty(t, byPath["sub"].Digest)
+}
+
+func TestSdJwt_SelectDisclosures(t *testing.T) {
+	sdJwt := issueRecursive(t)
+
+	t.Run("nested claim includes its parent", func(t *testing.T) {
+		selected, err := sdJwt.SelectDisclosures("address.locality")
+		require.NoError(t, err)
+		require.Len(t, selected, 2)
+		assert.Equal(t, "address", *selected[0].Key)
+		assert.Equal(t, "locality", *selected[1].Key)
+
+		sdJwt.Disclosures = selected
+		disclosed, err := sdJwt.GetDisclosedClaims()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"country": "US", "locality": "Anytown"}, disclosed["address"])
+		assert.NotContains(t, disclosed, "nationalities")
+		assert.NotContains(t, disclosed, "sub")
+	})
+
+	t.Run("array elements", func(t *testing.T) {
+		sdJwt := issueRecursive(t)
+		selected, err := sdJwt.SelectDisclosures("nationalities[*]", "sub")
+		require.NoError(t, err)
+		assert.Len(t, selected, 4)
+
+		sdJwt.Disclosures = selected
+		disclosed, err := sdJwt.GetDisclosedClaims()
+		require.NoError(t, err)
+		assert.Equal(t, []any{"US", "DE"}, disclosed["nationalities"])
+		assert.Equal(t, "user_42", disclosed["sub"])
+	})
+
+	t.Run("unknown claim", func(t *testing.T) {
+		_, err := sdJwt.SelectDisclosures("address.region")
+		require.Error(t, err)
+		assert.Equal(t, "no disclosure found for claim address.region", err.Error())
+	})
+}
+
+func TestSdJwt_SelectDisclosures_Decoys(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	// decoys are placed at random positions, so the selection is repeated to cover different layouts
+	for range 20 {
+		claims := issuanceClaims()
+		claims["nationalities"] = []any{"US", "DE", "FR"}
+		sdJwt, err := go_sd_jwt.Issue(claims, go_sd_jwt.IssuanceOptions{
+			Signer:                 signer,
+			SelectivelyDisclosable: []string{"nationalities[0]", "nationalities[1]"},
+			DecoyDigests:           go_sd_jwt.DecoyDigests{Min: 2},
+		})
+		require.NoError(t, err)
+
+		selected, err := sdJwt.SelectDisclosures("nationalities[1]")
+		require.NoError(t, err)
+		require.Len(t, selected, 1)
+		assert.Equal(t, "DE", selected[0].Value)
+
+		selected, err = sdJwt.SelectDisclosures("nationalities[0]")
+		require.NoError(t, err)
+		require.Len(t, selected, 1)
+		assert.Equal(t, "US", selected[0].Value)
+
+		// the third element is included in plaintext
+		_, err = sdJwt.SelectDisclosures("nationalities[2]")
+		require.Error(t, err)
+		assert.Equal(t, "no disclosure found for claim nationalities[2]", err.Error())
+	}
+}
@@ -1126,6 +1126,195 @@ func TestE2E_NoTopLevelSdClaim(t *testing.T) {
 	})
 }
 
+func TestE2E_IssueWithClaimPaths(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	if err != nil {
+		t.Fatalf("error creating issuer signer: %s", err.Error())
+	}
+
+	inputData := map[string]any{
+		"verified_claims": map[string]any{
+			"verification": map[string]any{
+				"trust_framework": "de_aml",
+				"evidence": []map[string]any{
+					{
+						"type": "document",
+						"document": map[string]any{
+							"type": "idcard",
+							"issuer": map[string]any{
+								"name":    "Stadt Augsburg",
+								"country": "DE",
+							},
+							"number":           "53554554",
+							"date_of_issuance": "2010-03-23",
+						},
+					},
+				},
+			},
+			"claims": map[string]any{
+				"given_name":    "Max",
+				"nationalities": []any{"DE"},
+			},
+		},
+	}
+
+	sdJwt, err := go_sd_jwt.Issue(inputData, go_sd_jwt.IssuanceOptions{
+		Signer: issuerSigner,
+		Header: map[string]any{"typ": "example+sd-jwt"},
+		SelectivelyDisclosable: []string{
+			"verified_claims.verification.evidence[0].document.issuer",
+			"verified_claims.verification.evidence[0].document.number",
+			"verified_claims.verification.evidence[0].document.date_of_issuance",
+			"verified_claims.claims.nationalities[*]",
+		},
+	})
+	if err != nil {
+		t.Fatalf("error issuing sd jwt: %s", err.Error())
+	}
+
+	token, err := sdJwt.Token()
+	if err != nil {
+		t.Fatalf("error creating token: %s", err.Error())
+	}
+
+	received, err := go_sd_jwt.New(*token)
+	if err != nil {
+		t.Fatalf("error parsing issued token: %s", err.Error())
+	}
+	if err = received.Verify(go_sd_jwt.VerificationOptions{IssuerKey: issuerSigner.Public()}); err != nil {
+		t.Fatalf("error verifying issued token: %s", err.Error())
+	}
+	if len(received.Disclosures) != 4 {
+		t.Errorf("expected 4 disclosures, got: %d", len(received.Disclosures))
+	}
+
+	verification := received.Body["verified_claims"].(map[string]any)["verification"].(map[string]any)
+	keyNotPresent(t, verification, "_sd")
+	issuedDocument := keyPresent(t, verification, "evidence").([]any)[0].(map[string]any)["document"].(map[string]any)
+	keyNotPresent(t, issuedDocument, "number")
+	keyPresent(t, issuedDocument, "_sd")
+
+	disclosedClaims, err := received.GetDisclosedClaims()
+	if err != nil {
+		t.Fatalf("error disclosing claims: %s", err.Error())
+	}
+
+	verification = disclosedClaims["verified_claims"].(map[string]any)["verification"].(map[string]any)
+	document := keyPresent(t, verification, "evidence").([]any)[0].(map[string]any)["document"].(map[string]any)
+	keyNotPresent(t, document, "_sd")
+	keyPresent(t, document, "issuer")
+	keyPresent(t, document, "number")
+	keyPresent(t, document, "date_of_issuance")
+
+	nationalities := disclosedClaims["verified_claims"].(map[string]any)["claims"].(map[string]any)["nationalities"].([]any)
+	if len(nationalities) != 1 || nationalities[0] != "DE" {
+		t.Errorf("incorrect nationalities disclosed: %v", nationalities)
+	}
+}
+
+func TestE2E_IssueWithRecursiveClaimPaths(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	if err != nil {
+		t.Fatalf("error creating issuer signer: %s", err.Error())
+	}
+
+	inputData := map[string]any{
+		"verified_claims": map[string]any{
+			"verification": map[string]any{
+				"trust_framework": "de_aml",
+				"evidence": []map[string]any{
+					{
+						"type": "document",
+						"document": map[string]any{
+							"type": "idcard",
+							"issuer": map[string]any{
+								"name":    "Stadt Augsburg",
+								"country": "DE",
+							},
+							"number":           "53554554",
+							"date_of_issuance": "2010-03-23",
+						},
+					},
+				},
+			},
+			"claims": map[string]any{
+				"given_name":    "Max",
+				"nationalities": []any{"DE"},
+			},
+		},
+	}
+
+	sdJwt, err := go_sd_jwt.Issue(inputData, go_sd_jwt.IssuanceOptions{
+		Signer: issuerSigner,
+		Header: map[string]any{"typ": "example+sd-jwt"},
+		SelectivelyDisclosable: []string{
+			"verified_claims.verification.evidence",
+			"verified_claims.verification.evidence[0].document.issuer",
+			"verified_claims.verification.evidence[0].document.number",
+			"verified_claims.verification.evidence[0].document.date_of_issuance",
+			"verified_claims.claims.nationalities[*]",
+		},
+	})
+	if err != nil {
+		t.Fatalf("error issuing sd jwt: %s", err.Error())
+	}
+
+	token, err := sdJwt.Token()
+	if err != nil {
+		t.Fatalf("error creating token: %s", err.Error())
+	}
+
+	received, err := go_sd_jwt.New(*token)
+	if err != nil {
+		t.Fatalf("error parsing issued token: %s", err.Error())
+	}
+	if err = received.Verify(go_sd_jwt.VerificationOptions{IssuerKey: issuerSigner.Public()}); err != nil {
+		t.Fatalf("error verifying issued token: %s", err.Error())
+	}
+	if len(received.Disclosures) != 5 {
+		t.Errorf("expected 5 disclosures, got: %d", len(received.Disclosures))
+	}
+
+	verification := received.Body["verified_claims"].(map[string]any)["verification"].(map[string]any)
+	keyNotPresent(t, verification, "evidence")
+	keyPresent(t, verification, "_sd")
+
+	// the evidence disclosure holds the digests of the document claims disclosed within it
+	tree, err := received.DisclosureTree()
+	if err != nil {
+		t.Fatalf("error building disclosure tree: %s", err.Error())
+	}
+	var evidence *go_sd_jwt.DisclosureNode
+	for _, n := range tree {
+		if n.Disclosure.Key != nil && *n.Disclosure.Key == "evidence" {
+			evidence = n
+		}
+	}
+	if evidence == nil {
+		t.Fatalf("expected a disclosure for evidence")
+	}
+	if len(evidence.Children) != 3 {
+		t.Errorf("expected 3 disclosures within evidence, got: %d", len(evidence.Children))
+	}
+
+	disclosedClaims, err := received.GetDisclosedClaims()
+	if err != nil {
+		t.Fatalf("error disclosing claims: %s", err.Error())
+	}
+
+	verification = disclosedClaims["verified_claims"].(map[string]any)["verification"].(map[string]any)
+	document := keyPresent(t, verification, "evidence").([]any)[0].(map[string]any)["document"].(map[string]any)
+	keyNotPresent(t, document, "_sd")
+	keyPresent(t, document, "issuer")
+	keyPresent(t, document, "number")
+	keyPresent(t, document, "date_of_issuance")
+
+	nationalities := disclosedClaims["verified_claims"].(map[string]any)["claims"].(map[string]any)["nationalities"].([]any)
+	if len(nationalities) != 1 || nationalities[0] != "DE" {
+		t.Errorf("incorrect nationalities disclosed: %v", nationalities)
+	}
+}
+
 func keyPresent(t *testing.T, data map[string]any, key string) any {
 	val, ok := data[key]
 	if !ok {
@@ -0,0 +1,219 @@
+package go_sd_jwt
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// registeredHeaders are the header parameters registered by RFC 7515 and RFC 7518, which must not be listed in crit
+var registeredHeaders = []string{
+	"alg", "jku", "jwk", "kid", "x5u", "x5c", "x5t", "x5t#S256", "typ", "cty", "crit",
+	"enc", "zip", "epk", "apu", "apv", "iv", "tag", "p2s", "p2c",
+}
+
+// buildHeader creates the issuer JWT header from the provided options
+func buildHeader(opts IssuanceOptions, alg string, publicKey crypto.PublicKey) (map[string]any, error) {
+	head := make(map[string]any, len(opts.Header)+6)
+	for k, v := range opts.Header {
+		head[k] = v
+	}
+
+	if headAlg, ok := head["alg"]; ok && headAlg != alg {
+		return nil, fmt.Errorf("header alg %v does not match signing algorithm %s", headAlg, alg)
+	}
+	head["alg"] = alg
+
+	set := func(name, field string, value any) error {
+		if _, ok := head[name]; ok {
+			return fmt.Errorf("header %s is set in both Header and %s", name, field)
+		}
+		head[name] = value
+		return nil
+	}
+	if opts.Typ != "" {
+		if err := set("typ", "Typ", opts.Typ); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Kid != "" {
+		if err := set("kid", "Kid", opts.Kid); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Cty != "" {
+		if err := set("cty", "Cty", opts.Cty); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.X5c) > 0 {
+		if err := checkCertificateMatchesKey(opts.X5c[0], publicKey); err != nil {
+			return nil, err
+		}
+		chain := make([]any, len(opts.X5c))
+		for i, cert := range opts.X5c {
+			if cert == nil {
+				return nil, errors.New("x5c must not contain a nil certificate")
+			}
+			chain[i] = base64.StdEncoding.EncodeToString(cert.Raw)
+		}
+		if err := set("x5c", "X5c", chain); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.Crit) > 0 {
+		crit := make([]any, len(opts.Crit))
+		for i, name := range opts.Crit {
+			crit[i] = name
+		}
+		if err := set("crit", "Crit", crit); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateHeader(head); err != nil {
+		return nil, err
+	}
+	return head, nil
+}
+
+func checkCertificateMatchesKey(cert *x509.Certificate, pub crypto.PublicKey) error {
+	if cert == nil {
+		return errors.New("x5c must not contain a nil certificate")
+	}
+	if !publicKeysEqual(cert.PublicKey, pub) {
+		return errors.New("the first x5c certificate does not match the signing key")
+	}
+	return nil
+}
+
+// validateHeader checks the types of the typ, kid, cty, x5c and crit header parameters. Every name listed in crit
+// must be an extension header parameter present in the header.
+func validateHeader(head map[string]any) error {
+	for _, name := range []string{"typ", "kid", "cty"} {
+		if v, ok := head[name]; ok {
+			if _, ok := v.(string); !ok {
+				return fmt.Errorf("header %s must be a string", name)
+			}
+		}
+	}
+
+	if v, ok := head["x5c"]; ok {
+		chain, ok := v.([]any)
+		if !ok || len(chain) == 0 {
+			return errors.New("header x5c must be a non-empty array of certificates")
+		}
+		for _, cert := range chain {
+			strCert, ok := cert.(string)
+			if !ok {
+				return errors.New("header x5c must be a non-empty array of certificates")
+			}
+			if _, err := base64.StdEncoding.DecodeString(strCert); err != nil {
+				return fmt.Errorf("header x5c contains an invalid certificate encoding: %s", err.Error())
+			}
+		}
+	}
+
+	if v, ok := head["crit"]; ok {
+		crit, ok := v.([]any)
+		if !ok || len(crit) == 0 {
+			return errors.New("header crit must be a non-empty array of header names")
+		}
+		seen := map[string]bool{}
+		for _, name := range crit {
+			strName, ok := name.(string)
+			if !ok || strName == "" {
+				return errors.New("header crit must be a non-empty array of header names")
+			}
+			if seen[strName] {
+				return fmt.Errorf("header crit lists %s more than once", strName)
+			}
+			seen[strName] = true
+			if slices.Contains(registeredHeaders, strName) {
+				return fmt.Errorf("header crit must not list the registered header %s", strName)
+			}
+			if _, ok := head[strName]; !ok {
+				return fmt.Errorf("header crit lists %s which is not present in the header", strName)
+			}
+		}
+	}
+	return nil
+}
+
+// checkCritUnderstood returns an error if crit lists any header parameter not in the understood list
+func checkCritUnderstood(head map[string]any, understood []string) error {
+	crit, _ := head["crit"].([]any)
+	for _, name := range crit {
+		if strName, _ := name.(string); !slices.Contains(understood, strName) {
+			return fmt.Errorf("critical header %v is not understood", name)
+		}
+	}
+	return nil
+}
+
+func (s *SdJwt) stringHeader(name string) *string {
+	if v, ok := s.Head[name].(string); ok {
+		return &v
+	}
+	return nil
+}
+
+// Typ returns the typ header of the issuer JWT, or nil if not present
+func (s *SdJwt) Typ() *string {
+	return s.stringHeader("typ")
+}
+
+// Kid returns the kid header of the issuer JWT, or nil if not present
+func (s *SdJwt) Kid() *string {
+	return s.stringHeader("kid")
+}
+
+// Cty returns the cty header of the issuer JWT, or nil if not present
+func (s *SdJwt) Cty() *string {
+	return s.stringHeader("cty")
+}
+
+// Crit returns the header names listed in the crit header of the issuer JWT, or nil if not present
+func (s *SdJwt) Crit() []string {
+	crit, _ := s.Head["crit"].([]any)
+	var names []string
+	for _, name := range crit {
+		if strName, ok := name.(string); ok {
+			names = append(names, strName)
+		}
+	}
+	return names
+}
+
+// X5c returns the parsed certificate chain from the x5c header of the issuer JWT, or nil if not present.
+// The chain is not validated, the first certificate is the one containing the issuer key.
+func (s *SdJwt) X5c() ([]*x509.Certificate, error) {
+	v, ok := s.Head["x5c"]
+	if !ok {
+		return nil, nil
+	}
+	chain, ok := v.([]any)
+	if !ok {
+		return nil, errors.New("header x5c must be a non-empty array of certificates")
+	}
+
+	certs := make([]*x509.Certificate, len(chain))
+	for i, cert := range chain {
+		strCert, ok := cert.(string)
+		if !ok {
+			return nil, errors.New("header x5c must be a non-empty array of certificates")
+		}
+		der, err := base64.StdEncoding.DecodeString(strCert)
+		if err != nil {
+			return nil, fmt.Errorf("header x5c contains an invalid certificate encoding: %s", err.Error())
+		}
+		certs[i], err = x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("header x5c contains an invalid certificate: %s", err.Error())
+		}
+	}
+	return certs, nil
+}
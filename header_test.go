@@ -0,0 +1,177 @@
+package go_sd_jwt_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func selfSignedCertificate(t *testing.T, key crypto.Signer) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "issuer.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestIssue_Header(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cert := selfSignedCertificate(t, key)
+
+	sdJwt, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+		Signer: key,
+		Alg:    "ES256",
+		Typ:    "dc+sd-jwt",
+		Kid:    "key-1",
+		Cty:    "application/json",
+		X5c:    []*x509.Certificate{cert},
+		Header: map[string]any{"ext": "value"},
+		Crit:   []string{"ext"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"alg":  "ES256",
+		"typ":  "dc+sd-jwt",
+		"kid":  "key-1",
+		"cty":  "application/json",
+		"x5c":  []any{base64.StdEncoding.EncodeToString(cert.Raw)},
+		"ext":  "value",
+		"crit": []any{"ext"},
+	}, sdJwt.Head)
+
+	require.NotNil(t, sdJwt.Typ())
+	assert.Equal(t, "dc+sd-jwt", *sdJwt.Typ())
+	require.NotNil(t, sdJwt.Kid())
+	assert.Equal(t, "key-1", *sdJwt.Kid())
+	require.NotNil(t, sdJwt.Cty())
+	assert.Equal(t, "application/json", *sdJwt.Cty())
+	assert.Equal(t, []string{"ext"}, sdJwt.Crit())
+
+	chain, err := sdJwt.X5c()
+	require.NoError(t, err)
+	require.Len(t, chain, 1)
+	assert.True(t, chain[0].Equal(cert))
+
+	err = sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: &key.PublicKey})
+	require.Error(t, err)
+	assert.Equal(t, "invalid token: critical header ext is not understood", err.Error())
+	require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: &key.PublicKey, UnderstoodHeaders: []string{"ext"}}))
+}
+
+func TestIssue_CritVerification(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	issued, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+		Signer:                 key,
+		Alg:                    "ES256",
+		Header:                 map[string]any{"ext": "value"},
+		Crit:                   []string{"ext"},
+		SelectivelyDisclosable: []string{"given_name"},
+	})
+	require.NoError(t, err)
+	token, err := issued.Token()
+	require.NoError(t, err)
+
+	// the critical header must be understood by every verifier, including one using the default options
+	sdJwt, err := go_sd_jwt.New(*token)
+	require.NoError(t, err)
+	err = sdJwt.Verify(go_sd_jwt.VerificationOptions{})
+	require.Error(t, err)
+	assert.Equal(t, "invalid token: critical header ext is not understood", err.Error())
+
+	require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: &key.PublicKey, UnderstoodHeaders: []string{"ext"}}))
+}
+
+func TestIssue_HeaderAccessorsAbsent(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	sdJwt, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{Signer: key, Alg: "ES256"})
+	require.NoError(t, err)
+
+	assert.Nil(t, sdJwt.Typ())
+	assert.Nil(t, sdJwt.Kid())
+	assert.Nil(t, sdJwt.Cty())
+	assert.Nil(t, sdJwt.Crit())
+	chain, err := sdJwt.X5c()
+	require.NoError(t, err)
+	assert.Nil(t, chain)
+}
+
+func TestIssue_HeaderErrors(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		opts go_sd_jwt.IssuanceOptions
+		err  string
+	}{
+		{
+			name: "kid set twice",
+			opts: go_sd_jwt.IssuanceOptions{Kid: "key-1", Header: map[string]any{"kid": "key-2"}},
+			err:  "invalid issuance: header kid is set in both Header and Kid",
+		},
+		{
+			name: "x5c does not match the signing key",
+			opts: go_sd_jwt.IssuanceOptions{X5c: []*x509.Certificate{selfSignedCertificate(t, otherKey)}},
+			err:  "invalid issuance: the first x5c certificate does not match the signing key",
+		},
+		{
+			name: "x5c contains a nil certificate",
+			opts: go_sd_jwt.IssuanceOptions{X5c: []*x509.Certificate{selfSignedCertificate(t, key), nil}},
+			err:  "invalid issuance: x5c must not contain a nil certificate",
+		},
+		{
+			name: "crit lists a registered header",
+			opts: go_sd_jwt.IssuanceOptions{Kid: "key-1", Crit: []string{"kid"}},
+			err:  "invalid issuance: header crit must not list the registered header kid",
+		},
+		{
+			name: "crit lists a missing header",
+			opts: go_sd_jwt.IssuanceOptions{Crit: []string{"ext"}},
+			err:  "invalid issuance: header crit lists ext which is not present in the header",
+		},
+		{
+			name: "crit lists a header twice",
+			opts: go_sd_jwt.IssuanceOptions{Header: map[string]any{"ext": true}, Crit: []string{"ext", "ext"}},
+			err:  "invalid issuance: header crit lists ext more than once",
+		},
+		{
+			name: "kid is not a string",
+			opts: go_sd_jwt.IssuanceOptions{Header: map[string]any{"kid": 1}},
+			err:  "invalid issuance: header kid must be a string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Signer = key
+			tt.opts.Alg = "ES256"
+			_, err := go_sd_jwt.Issue(issuanceClaims(), tt.opts)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
@@ -4,3 +4,4 @@ import "errors"
 
 var ErrInvalidToken = errors.New("invalid token: ")
 var ErrInvalidDisclosure = errors.New("invalid disclosure: ")
+var ErrInvalidIssuance = errors.New("invalid issuance: ")
@@ -0,0 +1,731 @@
+// Package jsonschema validates JSON values against the subset of JSON Schema (draft 2020-12 and draft-07) used by
+// credential schemas: type, enum, const, the string, number, object and array assertions, the allOf, anyOf, oneOf, not
+// and if/then/else applicators, and $ref to definitions within the same schema. Schemas using any other keyword are
+// rejected rather than partially applied.
+package jsonschema
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+)
+
+// maxRefDepth limits the number of nested $ref resolutions, guarding against schemas which reference themselves
+const maxRefDepth = 64
+
+type keywordKind int
+
+const (
+	// annotation keywords do not affect validation
+	annotation keywordKind = iota
+	// assertion keywords are validated against the instance
+	assertion
+	// numeric keywords are assertions whose value must be a number
+	numeric
+	// subschema keywords hold a single schema
+	subschema
+	// subschemaList keywords hold an array of schemas
+	subschemaList
+	// subschemaMap keywords hold an object whose values are schemas
+	subschemaMap
+)
+
+// keywords lists every supported keyword, Validate returns an error for a schema using any other keyword
+var keywords = map[string]keywordKind{
+	"$schema":              annotation,
+	"$id":                  annotation,
+	"$comment":             annotation,
+	"title":                annotation,
+	"description":          annotation,
+	"default":              annotation,
+	"examples":             annotation,
+	"deprecated":           annotation,
+	"readOnly":             annotation,
+	"writeOnly":            annotation,
+	"$ref":                 assertion,
+	"type":                 assertion,
+	"enum":                 assertion,
+	"const":                assertion,
+	"pattern":              assertion,
+	"format":               assertion,
+	"required":             assertion,
+	"uniqueItems":          assertion,
+	"items":                assertion,
+	"minLength":            numeric,
+	"maxLength":            numeric,
+	"minimum":              numeric,
+	"maximum":              numeric,
+	"exclusiveMinimum":     numeric,
+	"exclusiveMaximum":     numeric,
+	"multipleOf":           numeric,
+	"minProperties":        numeric,
+	"maxProperties":        numeric,
+	"minItems":             numeric,
+	"maxItems":             numeric,
+	"additionalProperties": subschema,
+	"additionalItems":      subschema,
+	"contains":             subschema,
+	"not":                  subschema,
+	"if":                   subschema,
+	"then":                 subschema,
+	"else":                 subschema,
+	"allOf":                subschemaList,
+	"anyOf":                subschemaList,
+	"oneOf":                subschemaList,
+	"prefixItems":          subschemaList,
+	"properties":           subschemaMap,
+	"patternProperties":    subschemaMap,
+	"$defs":                subschemaMap,
+	"definitions":          subschemaMap,
+}
+
+// typeNames lists the values type may take
+var typeNames = []string{"null", "boolean", "string", "number", "integer", "object", "array"}
+
+// Error is a single validation failure at the claim path of the invalid value
+type Error struct {
+	Path    go_sd_jwt.ClaimPath
+	Message string
+}
+
+// Options relaxes validation for values which may have been withheld
+type Options struct {
+	// OptionalProperty reports whether a required property of the object at path may be absent
+	OptionalProperty func(path go_sd_jwt.ClaimPath, name string) bool
+	// OptionalItems reports whether the array at path may contain fewer items than minItems
+	OptionalItems func(path go_sd_jwt.ClaimPath) bool
+}
+
+// Validate validates the instance, which must be in its JSON form (maps, slices, float64, string, bool or nil),
+// against the schema and returns every failure found
+func Validate(schema any, instance any, opts Options) ([]Error, error) {
+	v := &validator{root: schema, opts: opts, patterns: map[string]*regexp.Regexp{}}
+	if err := v.check(schema, "#"); err != nil {
+		return nil, err
+	}
+	if err := v.validate(schema, instance, nil, 0); err != nil {
+		return nil, err
+	}
+	return v.errors, nil
+}
+
+type validator struct {
+	root     any
+	opts     Options
+	patterns map[string]*regexp.Regexp
+	errors   []Error
+}
+
+// check walks every subschema of the schema, returning an error if a keyword is not supported, has a value of the
+// wrong type or is a $ref which cannot be resolved. Locations are JSON pointers into the schema, e.g. #/properties/age
+func (v *validator) check(schema any, location string) error {
+	s, ok := schema.(map[string]any)
+	if !ok {
+		if _, ok := schema.(bool); ok {
+			return nil
+		}
+		return fmt.Errorf("invalid schema: %s must be an object or boolean", location)
+	}
+
+	for _, name := range sortedKeys(s) {
+		value := s[name]
+		at := location + "/" + escapePointer(name)
+		kind, ok := keywords[name]
+		if !ok || (name == "$id" && location != "#") {
+			return fmt.Errorf("invalid schema: unsupported keyword %s at %s", name, location)
+		}
+
+		switch kind {
+		case numeric:
+			n, ok := number(value)
+			if !ok {
+				return fmt.Errorf("invalid schema: %s must be a number", at)
+			}
+			if name == "multipleOf" && n <= 0 {
+				return fmt.Errorf("invalid schema: %s must be greater than 0", at)
+			}
+		case subschema:
+			if err := v.check(value, at); err != nil {
+				return err
+			}
+		case subschemaList:
+			if err := v.checkList(value, at); err != nil {
+				return err
+			}
+		case subschemaMap:
+			m, ok := value.(map[string]any)
+			if !ok {
+				return fmt.Errorf("invalid schema: %s must be an object", at)
+			}
+			for _, key := range sortedKeys(m) {
+				if name == "patternProperties" {
+					if _, err := v.pattern(key); err != nil {
+						return err
+					}
+				}
+				if err := v.check(m[key], at+"/"+escapePointer(key)); err != nil {
+					return err
+				}
+			}
+		}
+
+		switch name {
+		case "type":
+			if err := checkType(value, at); err != nil {
+				return err
+			}
+		case "required":
+			required, ok := value.([]any)
+			if !ok {
+				return fmt.Errorf("invalid schema: %s must be an array", at)
+			}
+			for i, r := range required {
+				if _, ok := r.(string); !ok {
+					return fmt.Errorf("invalid schema: %s/%d must be a string", at, i)
+				}
+			}
+		case "$ref":
+			ref, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("invalid schema: %s must be a string", at)
+			}
+			if _, err := v.resolveRef(ref); err != nil {
+				return err
+			}
+		case "pattern":
+			pattern, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("invalid schema: %s must be a string", at)
+			}
+			if _, err := v.pattern(pattern); err != nil {
+				return err
+			}
+		case "items":
+			// an array of items is the draft-07 form of prefixItems
+			if _, ok := value.([]any); ok {
+				if err := v.checkList(value, at); err != nil {
+					return err
+				}
+			} else if err := v.check(value, at); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkType checks the value of type is a type name or an array of type names
+func checkType(value any, location string) error {
+	switch t := value.(type) {
+	case string:
+		if !slices.Contains(typeNames, t) {
+			return fmt.Errorf("invalid schema: %s has an unknown type %s", location, t)
+		}
+	case []any:
+		for i, element := range t {
+			name, ok := element.(string)
+			if !ok {
+				return fmt.Errorf("invalid schema: %s/%d must be a string", location, i)
+			}
+			if !slices.Contains(typeNames, name) {
+				return fmt.Errorf("invalid schema: %s/%d has an unknown type %s", location, i, name)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid schema: %s must be a string or an array of strings", location)
+	}
+	return nil
+}
+
+func (v *validator) checkList(value any, location string) error {
+	list, ok := value.([]any)
+	if !ok {
+		return fmt.Errorf("invalid schema: %s must be an array", location)
+	}
+	for i, sub := range list {
+		if err := v.check(sub, fmt.Sprintf("%s/%d", location, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pattern returns the compiled regular expression, compiling each pattern once per validation
+func (v *validator) pattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: invalid pattern %s: %w", pattern, err)
+	}
+	v.patterns[pattern] = re
+	return re, nil
+}
+
+func (v *validator) fail(path go_sd_jwt.ClaimPath, format string, args ...any) {
+	v.errors = append(v.errors, Error{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches reports whether the instance is valid against the schema without recording any failures
+func (v *validator) matches(schema any, instance any, path go_sd_jwt.ClaimPath, depth int) (bool, error) {
+	sub := &validator{root: v.root, opts: v.opts, patterns: v.patterns}
+	if err := sub.validate(schema, instance, path, depth); err != nil {
+		return false, err
+	}
+	return len(sub.errors) == 0, nil
+}
+
+func (v *validator) validate(schema any, instance any, path go_sd_jwt.ClaimPath, depth int) error {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.fail(path, "no value is allowed")
+		}
+		return nil
+	case map[string]any:
+		return v.validateSchema(s, instance, path, depth)
+	default:
+		return fmt.Errorf("invalid schema at %s: must be an object or boolean", path)
+	}
+}
+
+func (v *validator) validateSchema(schema map[string]any, instance any, path go_sd_jwt.ClaimPath, depth int) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		if depth >= maxRefDepth {
+			return errors.New("invalid schema: $ref nesting is too deep")
+		}
+		target, err := v.resolveRef(ref)
+		if err != nil {
+			return err
+		}
+		if err := v.validate(target, instance, path, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if t, ok := schema["type"]; ok {
+		var types []string
+		switch value := t.(type) {
+		case string:
+			types = []string{value}
+		case []any:
+			for _, element := range value {
+				if name, ok := element.(string); ok {
+					types = append(types, name)
+				}
+			}
+		}
+		if !hasType(instance, types) {
+			v.fail(path, "must be of type %s", strings.Join(types, " or "))
+			return nil
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if equal(e, instance) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "must be one of the values in enum")
+		}
+	}
+	if c, ok := schema["const"]; ok && !equal(c, instance) {
+		v.fail(path, "must be equal to const")
+	}
+
+	var err error
+	switch value := instance.(type) {
+	case string:
+		err = v.validateString(schema, value, path)
+	case float64:
+		v.validateNumber(schema, value, path)
+	case map[string]any:
+		err = v.validateObject(schema, value, path, depth)
+	case []any:
+		err = v.validateArray(schema, value, path, depth)
+	}
+	if err != nil {
+		return err
+	}
+
+	return v.validateApplicators(schema, instance, path, depth)
+}
+
+func (v *validator) validateApplicators(schema map[string]any, instance any, path go_sd_jwt.ClaimPath, depth int) error {
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			if err := v.validate(sub, instance, path, depth); err != nil {
+				return err
+			}
+		}
+	}
+
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			ok, err := v.matches(sub, instance, path, depth)
+			if err != nil {
+				return err
+			}
+			if ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(path, "must match at least one schema in anyOf")
+		}
+	}
+
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			ok, err := v.matches(sub, instance, path, depth)
+			if err != nil {
+				return err
+			}
+			if ok {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.fail(path, "must match exactly one schema in oneOf, matched %d", matched)
+		}
+	}
+
+	if not, ok := schema["not"]; ok {
+		ok, err := v.matches(not, instance, path, depth)
+		if err != nil {
+			return err
+		}
+		if ok {
+			v.fail(path, "must not match the schema in not")
+		}
+	}
+
+	if condition, ok := schema["if"]; ok {
+		ok, err := v.matches(condition, instance, path, depth)
+		if err != nil {
+			return err
+		}
+		branch := "else"
+		if ok {
+			branch = "then"
+		}
+		if sub, ok := schema[branch]; ok {
+			if err := v.validate(sub, instance, path, depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *validator) validateString(schema map[string]any, value string, path go_sd_jwt.ClaimPath) error {
+	length := utf8.RuneCountInString(value)
+	if min, ok := number(schema["minLength"]); ok && float64(length) < min {
+		v.fail(path, "must be at least %v characters long", min)
+	}
+	if max, ok := number(schema["maxLength"]); ok && float64(length) > max {
+		v.fail(path, "must be at most %v characters long", max)
+	}
+
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := v.pattern(pattern)
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(value) {
+			v.fail(path, "must match the pattern %s", pattern)
+		}
+	}
+
+	if format, ok := schema["format"].(string); ok && !validFormat(format, value) {
+		v.fail(path, "must be a valid %s", format)
+	}
+	return nil
+}
+
+func validFormat(format, value string) bool {
+	switch format {
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "email":
+		_, err := mail.ParseAddress(value)
+		return err == nil && !strings.ContainsAny(value, "<> ")
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	default:
+		// unknown formats are annotations only
+		return true
+	}
+}
+
+func (v *validator) validateNumber(schema map[string]any, value float64, path go_sd_jwt.ClaimPath) {
+	if min, ok := number(schema["minimum"]); ok && value < min {
+		v.fail(path, "must be greater than or equal to %v", min)
+	}
+	if max, ok := number(schema["maximum"]); ok && value > max {
+		v.fail(path, "must be less than or equal to %v", max)
+	}
+	if min, ok := number(schema["exclusiveMinimum"]); ok && value <= min {
+		v.fail(path, "must be greater than %v", min)
+	}
+	if max, ok := number(schema["exclusiveMaximum"]); ok && value >= max {
+		v.fail(path, "must be less than %v", max)
+	}
+	if multiple, ok := number(schema["multipleOf"]); ok && multiple > 0 && !isMultiple(value, multiple) {
+		v.fail(path, "must be a multiple of %v", multiple)
+	}
+}
+
+func (v *validator) validateObject(schema map[string]any, value map[string]any, path go_sd_jwt.ClaimPath, depth int) error {
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name, ok := r.(string)
+			if !ok {
+				continue
+			}
+			if _, ok := value[name]; ok {
+				continue
+			}
+			if v.opts.OptionalProperty != nil && v.opts.OptionalProperty(path, name) {
+				continue
+			}
+			v.fail(path.Append(name), "is required")
+		}
+	}
+
+	if min, ok := number(schema["minProperties"]); ok && float64(len(value)) < min {
+		v.fail(path, "must have at least %v properties", min)
+	}
+	if max, ok := number(schema["maxProperties"]); ok && float64(len(value)) > max {
+		v.fail(path, "must have at most %v properties", max)
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	patternProperties, _ := schema["patternProperties"].(map[string]any)
+	additional, hasAdditional := schema["additionalProperties"]
+
+	for _, name := range sortedKeys(value) {
+		evaluated := false
+		if sub, ok := properties[name]; ok {
+			evaluated = true
+			if err := v.validate(sub, value[name], path.Append(name), depth); err != nil {
+				return err
+			}
+		}
+		for _, pattern := range sortedKeys(patternProperties) {
+			re, err := v.pattern(pattern)
+			if err != nil {
+				return err
+			}
+			if re.MatchString(name) {
+				evaluated = true
+				if err := v.validate(patternProperties[pattern], value[name], path.Append(name), depth); err != nil {
+					return err
+				}
+			}
+		}
+		if !evaluated && hasAdditional {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				v.fail(path.Append(name), "is not allowed")
+				continue
+			}
+			if err := v.validate(additional, value[name], path.Append(name), depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *validator) validateArray(schema map[string]any, value []any, path go_sd_jwt.ClaimPath, depth int) error {
+	if min, ok := number(schema["minItems"]); ok && float64(len(value)) < min {
+		if v.opts.OptionalItems == nil || !v.opts.OptionalItems(path) {
+			v.fail(path, "must have at least %v items", min)
+		}
+	}
+	if max, ok := number(schema["maxItems"]); ok && float64(len(value)) > max {
+		v.fail(path, "must have at most %v items", max)
+	}
+	if unique, ok := schema["uniqueItems"].(bool); ok && unique {
+	duplicates:
+		for i := range value {
+			for j := 0; j < i; j++ {
+				if equal(value[i], value[j]) {
+					v.fail(path, "must not contain duplicate items")
+					break duplicates
+				}
+			}
+		}
+	}
+
+	// prefixItems (2020-12) or an array of items (draft-07) validate elements by position, the remaining elements are
+	// validated by items (2020-12) or additionalItems (draft-07)
+	prefix, _ := schema["prefixItems"].([]any)
+	rest, hasRest := schema["items"]
+	if tuple, ok := rest.([]any); ok {
+		prefix = tuple
+		rest, hasRest = schema["additionalItems"]
+	}
+	for i, element := range value {
+		var sub any
+		switch {
+		case i < len(prefix):
+			sub = prefix[i]
+		case hasRest:
+			sub = rest
+		default:
+			continue
+		}
+		if err := v.validate(sub, element, path.Append(i), depth); err != nil {
+			return err
+		}
+	}
+
+	if contains, ok := schema["contains"]; ok {
+		found := false
+		for i, element := range value {
+			ok, err := v.matches(contains, element, path.Append(i), depth)
+			if err != nil {
+				return err
+			}
+			if ok {
+				found = true
+				break
+			}
+		}
+		if !found && (v.opts.OptionalItems == nil || !v.opts.OptionalItems(path)) {
+			v.fail(path, "must contain an item matching the schema in contains")
+		}
+	}
+	return nil
+}
+
+// resolveRef resolves a reference to a location within the root schema, e.g. #/$defs/address
+func (v *validator) resolveRef(ref string) (any, error) {
+	if ref == "#" {
+		return v.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("invalid schema: unsupported $ref %s, only references within the schema are supported", ref)
+	}
+
+	current := v.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		token, err := url.PathUnescape(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema: invalid $ref %s", ref)
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]any:
+			next, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("invalid schema: $ref %s not found", ref)
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("invalid schema: $ref %s not found", ref)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("invalid schema: $ref %s not found", ref)
+		}
+	}
+	return current, nil
+}
+
+func hasType(instance any, types []string) bool {
+	for _, t := range types {
+		switch t {
+		case "null":
+			if instance == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := instance.(bool); ok {
+				return true
+			}
+		case "string":
+			if _, ok := instance.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := instance.(float64); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := instance.(float64); ok && n == math.Trunc(n) {
+				return true
+			}
+		case "object":
+			if _, ok := instance.(map[string]any); ok {
+				return true
+			}
+		case "array":
+			if _, ok := instance.([]any); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isMultiple reports whether value is an integer multiple of multiple. The numbers are compared as the shortest decimals
+// which round trip to them, i.e. as written in JSON, so that 0.3 is a multiple of 0.1 despite the binary approximations
+func isMultiple(value, multiple float64) bool {
+	v, ok := new(big.Rat).SetString(strconv.FormatFloat(value, 'g', -1, 64))
+	if !ok {
+		return false
+	}
+	m, ok := new(big.Rat).SetString(strconv.FormatFloat(multiple, 'g', -1, 64))
+	if !ok || m.Sign() == 0 {
+		return false
+	}
+	return v.Quo(v, m).IsInt()
+}
+
+// escapePointer escapes a name for use as a JSON pointer reference token
+func escapePointer(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func number(v any) (float64, bool) {
+	n, ok := v.(float64)
+	return n, ok
+}
+
+func equal(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	return slices.Sorted(maps.Keys(m))
+}
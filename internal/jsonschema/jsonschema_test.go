@@ -0,0 +1,205 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, s string) any {
+	var v any
+	require.NoError(t, json.Unmarshal([]byte(s), &v))
+	return v
+}
+
+// failures formats each failure as its claim path followed by the message
+func failures(errs []jsonschema.Error) []string {
+	var messages []string
+	for _, err := range errs {
+		if len(err.Path) == 0 {
+			messages = append(messages, err.Message)
+			continue
+		}
+		messages = append(messages, err.Path.String()+" "+err.Message)
+	}
+	return messages
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		instance string
+		failures []string
+	}{
+		{name: "true schema", schema: `true`, instance: `"anything"`},
+		{name: "false schema", schema: `false`, instance: `"anything"`, failures: []string{"no value is allowed"}},
+		{name: "annotations", schema: `{"$schema": "https://json-schema.org/draft/2020-12/schema", "$id": "https://example.com/schema", "$comment": "c", "title": "t", "description": "d", "default": 1, "examples": [1], "deprecated": false, "readOnly": false, "writeOnly": false}`, instance: `1`},
+
+		{name: "type null", schema: `{"type": "null"}`, instance: `null`},
+		{name: "type boolean", schema: `{"type": "boolean"}`, instance: `true`},
+		{name: "type string", schema: `{"type": "string"}`, instance: `"a"`},
+		{name: "type number", schema: `{"type": "number"}`, instance: `1.5`},
+		{name: "type integer", schema: `{"type": "integer"}`, instance: `2.0`},
+		{name: "type object", schema: `{"type": "object"}`, instance: `{}`},
+		{name: "type array", schema: `{"type": "array"}`, instance: `[]`},
+		{name: "type mismatch", schema: `{"type": "string"}`, instance: `1`, failures: []string{"must be of type string"}},
+		{name: "type integer fractional", schema: `{"type": "integer"}`, instance: `1.5`, failures: []string{"must be of type integer"}},
+		{name: "type list", schema: `{"type": ["string", "null"]}`, instance: `null`},
+		{name: "type list mismatch", schema: `{"type": ["string", "null"]}`, instance: `false`, failures: []string{"must be of type string or null"}},
+
+		{name: "enum", schema: `{"enum": ["F", "M", {"a": 1}]}`, instance: `{"a": 1}`},
+		{name: "enum mismatch", schema: `{"enum": ["F", "M"]}`, instance: `"X"`, failures: []string{"must be one of the values in enum"}},
+		{name: "const", schema: `{"const": [1, "a"]}`, instance: `[1, "a"]`},
+		{name: "const mismatch", schema: `{"const": "DE"}`, instance: `"FR"`, failures: []string{"must be equal to const"}},
+
+		{name: "minLength counts characters", schema: `{"minLength": 2}`, instance: `"Kö"`},
+		{name: "minLength", schema: `{"minLength": 2}`, instance: `"K"`, failures: []string{"must be at least 2 characters long"}},
+		{name: "maxLength counts characters", schema: `{"maxLength": 4}`, instance: `"Köln"`},
+		{name: "maxLength", schema: `{"maxLength": 3}`, instance: `"Köln"`, failures: []string{"must be at most 3 characters long"}},
+		{name: "pattern", schema: `{"pattern": "^[A-Z]{2}$"}`, instance: `"DE"`},
+		{name: "pattern mismatch", schema: `{"pattern": "^[A-Z]{2}$"}`, instance: `"DEU"`, failures: []string{"must match the pattern ^[A-Z]{2}$"}},
+		{name: "pattern ignores other types", schema: `{"pattern": "^[A-Z]{2}$"}`, instance: `12`},
+
+		{name: "format date", schema: `{"format": "date"}`, instance: `"1984-01-26"`},
+		{name: "format date invalid", schema: `{"format": "date"}`, instance: `"26/01/1984"`, failures: []string{"must be a valid date"}},
+		{name: "format date-time", schema: `{"format": "date-time"}`, instance: `"2024-01-01T12:00:00Z"`},
+		{name: "format date-time invalid", schema: `{"format": "date-time"}`, instance: `"2024-01-01"`, failures: []string{"must be a valid date-time"}},
+		{name: "format email", schema: `{"format": "email"}`, instance: `"erika@example.com"`},
+		{name: "format email invalid", schema: `{"format": "email"}`, instance: `"Erika <erika@example.com>"`, failures: []string{"must be a valid email"}},
+		{name: "format uri", schema: `{"format": "uri"}`, instance: `"https://example.com/path"`},
+		{name: "format uri invalid", schema: `{"format": "uri"}`, instance: `"example.com"`, failures: []string{"must be a valid uri"}},
+		{name: "format unknown", schema: `{"format": "hostname"}`, instance: `"not a hostname"`},
+
+		{name: "minimum", schema: `{"minimum": 0}`, instance: `0`},
+		{name: "minimum below", schema: `{"minimum": 0}`, instance: `-1`, failures: []string{"must be greater than or equal to 0"}},
+		{name: "maximum", schema: `{"maximum": 150}`, instance: `150`},
+		{name: "maximum above", schema: `{"maximum": 150}`, instance: `151`, failures: []string{"must be less than or equal to 150"}},
+		{name: "exclusiveMinimum", schema: `{"exclusiveMinimum": 0}`, instance: `0.5`},
+		{name: "exclusiveMinimum equal", schema: `{"exclusiveMinimum": 0}`, instance: `0`, failures: []string{"must be greater than 0"}},
+		{name: "exclusiveMaximum", schema: `{"exclusiveMaximum": 1}`, instance: `0.5`},
+		{name: "exclusiveMaximum equal", schema: `{"exclusiveMaximum": 1}`, instance: `1`, failures: []string{"must be less than 1"}},
+		{name: "multipleOf integer", schema: `{"multipleOf": 5}`, instance: `25`},
+		{name: "multipleOf integer mismatch", schema: `{"multipleOf": 5}`, instance: `26`, failures: []string{"must be a multiple of 5"}},
+		{name: "multipleOf decimal", schema: `{"multipleOf": 0.1}`, instance: `0.3`},
+		{name: "multipleOf decimal large", schema: `{"multipleOf": 0.01}`, instance: `1234567.89`},
+		{name: "multipleOf decimal mismatch", schema: `{"multipleOf": 0.1}`, instance: `0.35`, failures: []string{"must be a multiple of 0.1"}},
+		{name: "multipleOf exponent", schema: `{"multipleOf": 1e-7}`, instance: `3e-7`},
+
+		{name: "required", schema: `{"required": ["given_name"]}`, instance: `{"given_name": "Erika"}`},
+		{name: "required missing", schema: `{"required": ["given_name", "family_name"]}`, instance: `{}`, failures: []string{"given_name is required", "family_name is required"}},
+		{name: "minProperties", schema: `{"minProperties": 2}`, instance: `{"a": 1}`, failures: []string{"must have at least 2 properties"}},
+		{name: "maxProperties", schema: `{"maxProperties": 1}`, instance: `{"a": 1, "b": 2}`, failures: []string{"must have at most 1 properties"}},
+		{name: "properties", schema: `{"properties": {"age": {"type": "integer"}}}`, instance: `{"age": 42, "other": "x"}`},
+		{name: "properties mismatch", schema: `{"properties": {"address": {"properties": {"locality": {"type": "string"}}}}}`, instance: `{"address": {"locality": 1}}`, failures: []string{"address.locality must be of type string"}},
+		{name: "patternProperties", schema: `{"patternProperties": {"^[a-z]{2}$": {"type": "string"}}}`, instance: `{"de": "Köln", "fr": 1, "other": 1}`, failures: []string{"fr must be of type string"}},
+		{name: "additionalProperties false", schema: `{"properties": {"a": true}, "patternProperties": {"^x-": true}, "additionalProperties": false}`, instance: `{"a": 1, "x-b": 2, "c": 3}`, failures: []string{"c is not allowed"}},
+		{name: "additionalProperties schema", schema: `{"properties": {"a": true}, "additionalProperties": {"type": "string"}}`, instance: `{"a": 1, "b": "x", "c": 3}`, failures: []string{"c must be of type string"}},
+
+		{name: "minItems", schema: `{"minItems": 1}`, instance: `[]`, failures: []string{"must have at least 1 items"}},
+		{name: "maxItems", schema: `{"maxItems": 1}`, instance: `[1, 2]`, failures: []string{"must have at most 1 items"}},
+		{name: "uniqueItems", schema: `{"uniqueItems": true}`, instance: `["DE", "FR"]`},
+		{name: "uniqueItems duplicate", schema: `{"uniqueItems": true}`, instance: `["DE", "FR", "DE"]`, failures: []string{"must not contain duplicate items"}},
+		{name: "uniqueItems several duplicates", schema: `{"uniqueItems": true}`, instance: `["DE", "DE", "DE", "FR", "FR"]`, failures: []string{"must not contain duplicate items"}},
+		{name: "items", schema: `{"items": {"type": "string"}}`, instance: `["DE", 1]`, failures: []string{"[1] must be of type string"}},
+		{name: "prefixItems", schema: `{"prefixItems": [{"type": "string"}, {"type": "integer"}], "items": false}`, instance: `["a", "b", 3]`, failures: []string{"[1] must be of type integer", "[2] no value is allowed"}},
+		{name: "items array", schema: `{"items": [{"type": "string"}], "additionalItems": {"type": "integer"}}`, instance: `["a", 1, "c"]`, failures: []string{"[2] must be of type integer"}},
+		{name: "contains", schema: `{"contains": {"const": "DE"}}`, instance: `["FR", "DE"]`},
+		{name: "contains missing", schema: `{"contains": {"const": "DE"}}`, instance: `["FR"]`, failures: []string{"must contain an item matching the schema in contains"}},
+
+		{name: "allOf", schema: `{"allOf": [{"type": "string"}, {"minLength": 3}]}`, instance: `"ab"`, failures: []string{"must be at least 3 characters long"}},
+		{name: "anyOf", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, instance: `1`},
+		{name: "anyOf mismatch", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, instance: `true`, failures: []string{"must match at least one schema in anyOf"}},
+		{name: "oneOf", schema: `{"oneOf": [{"type": "string"}, {"type": "integer"}]}`, instance: `1`},
+		{name: "oneOf several", schema: `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, instance: `1`, failures: []string{"must match exactly one schema in oneOf, matched 2"}},
+		{name: "not", schema: `{"not": {"type": "string"}}`, instance: `1`},
+		{name: "not mismatch", schema: `{"not": {"type": "string"}}`, instance: `"a"`, failures: []string{"must not match the schema in not"}},
+		{name: "if then", schema: `{"if": {"properties": {"country": {"const": "DE"}}}, "then": {"required": ["postal_code"]}, "else": {"required": ["region"]}}`, instance: `{"country": "DE"}`, failures: []string{"postal_code is required"}},
+		{name: "if else", schema: `{"if": {"properties": {"country": {"const": "DE"}}}, "then": {"required": ["postal_code"]}, "else": {"required": ["region"]}}`, instance: `{"country": "FR"}`, failures: []string{"region is required"}},
+
+		{name: "$ref $defs", schema: `{"properties": {"address": {"$ref": "#/$defs/address"}}, "$defs": {"address": {"required": ["locality"]}}}`, instance: `{"address": {}}`, failures: []string{"address.locality is required"}},
+		{name: "$ref definitions", schema: `{"properties": {"age": {"$ref": "#/definitions/age"}}, "definitions": {"age": {"type": "integer"}}}`, instance: `{"age": "42"}`, failures: []string{"age must be of type integer"}},
+		{name: "$ref escaped", schema: `{"$ref": "#/$defs/a~1b", "$defs": {"a/b": {"type": "string"}}}`, instance: `1`, failures: []string{"must be of type string"}},
+		{name: "$ref root", schema: `{"properties": {"child": {"$ref": "#"}}, "required": ["name"]}`, instance: `{"name": "a", "child": {}}`, failures: []string{"child.name is required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, err := jsonschema.Validate(parse(t, tt.schema), parse(t, tt.instance), jsonschema.Options{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.failures, failures(errs))
+		})
+	}
+}
+
+func TestValidate_InvalidSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{name: "not a schema", schema: `"string"`, err: "invalid schema: # must be an object or boolean"},
+		{name: "unevaluatedProperties", schema: `{"unevaluatedProperties": false}`, err: "invalid schema: unsupported keyword unevaluatedProperties at #"},
+		{name: "dependentRequired", schema: `{"dependentRequired": {"a": ["b"]}}`, err: "invalid schema: unsupported keyword dependentRequired at #"},
+		{name: "propertyNames", schema: `{"properties": {"address": {"propertyNames": {"maxLength": 3}}}}`, err: "invalid schema: unsupported keyword propertyNames at #/properties/address"},
+		{name: "unknown keyword in unused definition", schema: `{"$defs": {"a": {"items": {"minContains": 1}}}}`, err: "invalid schema: unsupported keyword minContains at #/$defs/a/items"},
+		{name: "unknown keyword in list", schema: `{"anyOf": [true, {"$anchor": "a"}]}`, err: "invalid schema: unsupported keyword $anchor at #/anyOf/1"},
+		{name: "nested $id", schema: `{"properties": {"a": {"$id": "https://example.com/a"}}}`, err: "invalid schema: unsupported keyword $id at #/properties/a"},
+		{name: "remote $ref", schema: `{"properties": {"a": {"$ref": "https://example.com/schema.json"}}}`, err: "invalid schema: unsupported $ref https://example.com/schema.json, only references within the schema are supported"},
+		{name: "missing $ref", schema: `{"$ref": "#/$defs/missing"}`, err: "invalid schema: $ref #/$defs/missing not found"},
+		{name: "recursive $ref", schema: `{"$ref": "#"}`, err: "invalid schema: $ref nesting is too deep"},
+		{name: "non numeric minimum", schema: `{"minimum": "0"}`, err: "invalid schema: #/minimum must be a number"},
+		{name: "boolean exclusiveMinimum", schema: `{"minimum": 0, "exclusiveMinimum": true}`, err: "invalid schema: #/exclusiveMinimum must be a number"},
+		{name: "zero multipleOf", schema: `{"multipleOf": 0}`, err: "invalid schema: #/multipleOf must be greater than 0"},
+		{name: "invalid pattern", schema: `{"pattern": "["}`, err: "invalid schema: invalid pattern [: error parsing regexp: missing closing ]: `[`"},
+		{name: "invalid patternProperties", schema: `{"patternProperties": {"[": true}}`, err: "invalid schema: invalid pattern [: error parsing regexp: missing closing ]: `[`"},
+		{name: "properties not an object", schema: `{"properties": ["a"]}`, err: "invalid schema: #/properties must be an object"},
+		{name: "allOf not an array", schema: `{"allOf": {"type": "string"}}`, err: "invalid schema: #/allOf must be an array"},
+		{name: "subschema not a schema", schema: `{"not": 1}`, err: "invalid schema: #/not must be an object or boolean"},
+		{name: "unknown type", schema: `{"properties": {"age": {"type": "int"}}}`, err: "invalid schema: #/properties/age/type has an unknown type int"},
+		{name: "unknown type in list", schema: `{"type": ["string", "date"]}`, err: "invalid schema: #/type/1 has an unknown type date"},
+		{name: "type not a string", schema: `{"type": 1}`, err: "invalid schema: #/type must be a string or an array of strings"},
+		{name: "type list element not a string", schema: `{"type": ["string", null]}`, err: "invalid schema: #/type/1 must be a string"},
+		{name: "required not an array", schema: `{"required": "given_name"}`, err: "invalid schema: #/required must be an array"},
+		{name: "required element not a string", schema: `{"required": ["given_name", 1]}`, err: "invalid schema: #/required/1 must be a string"},
+		{name: "escaped location", schema: `{"properties": {"a/b": {"x": 1}}}`, err: "invalid schema: unsupported keyword x at #/properties/a~1b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jsonschema.Validate(parse(t, tt.schema), map[string]any{}, jsonschema.Options{})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestValidate_Options(t *testing.T) {
+	schema := parse(t, `{
+		"required": ["given_name", "family_name"],
+		"properties": {"nationalities": {"minItems": 2, "contains": {"const": "DE"}}}
+	}`)
+	instance := parse(t, `{"nationalities": ["FR"]}`)
+
+	errs, err := jsonschema.Validate(schema, instance, jsonschema.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"given_name is required",
+		"family_name is required",
+		"nationalities must have at least 2 items",
+		"nationalities must contain an item matching the schema in contains",
+	}, failures(errs))
+
+	errs, err = jsonschema.Validate(schema, instance, jsonschema.Options{
+		OptionalProperty: func(path go_sd_jwt.ClaimPath, name string) bool {
+			return len(path) == 0 && name == "given_name"
+		},
+		OptionalItems: func(path go_sd_jwt.ClaimPath) bool {
+			return path.String() == "nationalities"
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"family_name is required"}, failures(errs))
+}
@@ -6,12 +6,24 @@ import (
 	"fmt"
 )
 
+// DefaultLength is the number of random bytes used for a salt, giving the 128 bits of entropy recommended by the
+// SD-JWT specification
+const DefaultLength = 16
+
 func NewSalt() (*string, error) {
-	randomBytes := make([]byte, 16)
-	_, err := rand.Read(randomBytes)
+	saltValue, err := New(DefaultLength)
 	if err != nil {
-		return nil, fmt.Errorf("error generating salt value: %w", err)
+		return nil, err
 	}
-	saltValue := base64.RawURLEncoding.EncodeToString(randomBytes)
 	return &saltValue, nil
 }
+
+// New returns the base64url encoding of length cryptographically secure random bytes
+func New(length int) (string, error) {
+	randomBytes := make([]byte, length)
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", fmt.Errorf("error generating salt value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"github.com/MichaelFraser99/go-sd-jwt/v2/disclosure"
 	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/model"
+	"math"
 	"reflect"
 	"strings"
+	"time"
 )
 
 func ValidateArrayClaims(s *[]any, currentDisclosure *disclosure.Disclosure, base64HashedDisclosure string) (found bool, err error) {
@@ -38,7 +40,10 @@ func ValidateArrayClaims(s *[]any, currentDisclosure *disclosure.Disclosure, bas
 
 			if ad.Digest != nil {
 				if *ad.Digest == base64HashedDisclosure {
-					(*s)[i] = currentDisclosure.Value
+					if currentDisclosure.Key != nil {
+						return false, errors.New("invalid disclosure format for array element")
+					}
+					(*s)[i] = CopyValue(currentDisclosure.Value)
 					return true, nil
 				}
 			}
@@ -65,7 +70,7 @@ func ValidateSDClaims(values *map[string]any, currentDisclosure *disclosure.Disc
 				sDigest := digest.(string)
 				if sDigest == base64HashedDisclosure {
 					if currentDisclosure.Key != nil {
-						(*values)[*currentDisclosure.Key] = currentDisclosure.Value
+						(*values)[*currentDisclosure.Key] = CopyValue(currentDisclosure.Value)
 						return true, nil
 					} else {
 						return false, errors.New("invalid disclosure format for _sd claim")
@@ -301,6 +306,19 @@ func CopyMap(m map[string]any) map[string]any {
 	return cp
 }
 
+// CopyValue returns a deep copy of the provided value if it is a map or slice, so disclosed values can be modified
+// without altering the disclosure they came from
+func CopyValue(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		return CopyMap(value)
+	case []any:
+		return CopySlice(value)
+	default:
+		return v
+	}
+}
+
 func CopySlice(s []any) []any {
 	cp := make([]any, len(s))
 	for i, v := range s {
@@ -316,3 +334,27 @@ func CopySlice(s []any) []any {
 	}
 	return cp
 }
+
+// NumericDate returns the value of a NumericDate claim as provided in a claims map or decoded from a token.
+// NumericDate values may be fractional, e.g. 1700000000.5
+func NumericDate(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, !math.IsNaN(n) && !math.IsInf(n, 0)
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// NumericTime returns the time of a NumericDate, keeping any fraction of a second
+func NumericTime(n float64) time.Time {
+	seconds, fraction := math.Modf(n)
+	return time.Unix(int64(seconds), int64(fraction*float64(time.Second)))
+}
@@ -0,0 +1,263 @@
+package go_sd_jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/MichaelFraser99/go-sd-jwt/v2/disclosure"
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+)
+
+// IssuanceOptions configures how Issue builds and signs an SD-JWT.
+// Signer is required by Issue, all other fields are optional.
+type IssuanceOptions struct {
+	// Signer signs the issuer JWT
+	Signer crypto.Signer
+	// PublicKey is the public key of the issuer, only used by PrepareIssuance when no Signer is provided
+	PublicKey crypto.PublicKey
+	// Alg is the JWS algorithm used to sign the issuer JWT (e.g. ES256). If empty, it is taken from the Signer when it is a go-jose signer
+	Alg string
+	// SdAlg is the IANA name of the hash algorithm used to calculate digests, defaults to sha-256
+	SdAlg string
+	// Header contains any additional header parameters for the issuer JWT
+	Header map[string]any
+	// Typ is the typ header of the issuer JWT, e.g. example+sd-jwt
+	Typ string
+	// Kid is the kid header identifying the issuer key
+	Kid string
+	// Cty is the cty header describing the content type of the payload
+	Cty string
+	// X5c is the certificate chain added as the x5c header, the first certificate must contain the public key of the issuer
+	X5c []*x509.Certificate
+	// Crit lists the extension header parameters provided in Header which recipients must understand to process the token.
+	// Verify rejects such a token unless every listed header is included in VerificationOptions.UnderstoodHeaders, so
+	// recipients, including this library's own Verify, must opt in to each of them.
+	Crit []string
+	// SelectivelyDisclosable lists the claim paths (see ParseClaimPath) of the claims and array elements which are to be made selectively disclosable
+	SelectivelyDisclosable []string
+	// DecoyDigests configures the number of decoy digests added alongside the digests of selectively disclosable claims
+	DecoyDigests DecoyDigests
+	// Cnf binds the SD-JWT to the key of the holder through the cnf claim (see NewJwkConfirmation and NewJktConfirmation)
+	Cnf *Confirmation
+	// SaltSource provides the salts for disclosures and decoy digests, defaults to RandomSaltSource with 16 byte salts
+	SaltSource SaltSource
+	// Validity adds the iat, nbf and exp claims and limits the validity window of the SD-JWT
+	Validity Validity
+	// ValidateClaims, when set, is called with the final claims, including those added through Validity and Cnf,
+	// before any are made selectively disclosable. The claims must not be modified. Issuance fails if it returns an error
+	ValidateClaims func(claims map[string]any) error
+}
+
+// Issue creates a new signed SD-JWT from the provided claims.
+// Every object claim matched by a path in opts.SelectivelyDisclosable is removed from the payload and replaced with a
+// digest in the _sd claim of its parent object. Every matched array element is replaced in place with a {"...": digest}
+// entry. The disclosures for all digests are returned on the resulting SdJwt. The provided claims map is not modified.
+// When both a claim and claims beneath it are selectively disclosable (e.g. address and address.street_address), the
+// nested claims are processed first so the disclosure of the parent contains their digests (recursive disclosures).
+// The resulting structure is available through SdJwt.DisclosureTree.
+// The returned SdJwt is the result of parsing the issued token, so it can be verified and presented like any other SdJwt.
+func Issue(claims map[string]any, opts IssuanceOptions) (*SdJwt, error) {
+	if opts.Signer == nil {
+		return nil, fmt.Errorf("%wa signer must be provided", e.ErrInvalidIssuance)
+	}
+
+	prepared, err := PrepareIssuance(claims, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := sign(opts.Signer, prepared.Alg, prepared.SigningInput)
+	if err != nil {
+		return nil, fmt.Errorf("error signing sd-jwt: %w", err)
+	}
+
+	return prepared.Finish(sig)
+}
+
+// PreparedIssuance is an SD-JWT which has been built by PrepareIssuance but not yet signed.
+// SigningInput is the exact JWS signing input (the base64url encoded header and payload joined by a '.') which must be
+// signed with Alg, e.g. by a remote signing service or HSM, before calling Finish.
+type PreparedIssuance struct {
+	SigningInput string
+	Alg          string
+
+	publicKey   crypto.PublicKey
+	disclosures []disclosure.Disclosure
+}
+
+// PrepareIssuance builds an SD-JWT from the provided claims in the same way as Issue, without signing it.
+// opts.Signer is optional: when it is not provided, opts.Alg is required and the public key of the issuer is taken
+// from opts.PublicKey or, failing that, the first certificate in opts.X5c. When a public key is known, it is checked
+// against the algorithm and used by Finish to verify the signature.
+func PrepareIssuance(claims map[string]any, opts IssuanceOptions) (*PreparedIssuance, error) {
+	alg, err := resolveAlg(opts.Signer, opts.Alg)
+	if err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+	}
+	if _, err := algToHash(alg); err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+	}
+
+	publicKey := opts.PublicKey
+	if publicKey == nil && opts.Signer != nil {
+		publicKey = opts.Signer.Public()
+	}
+	if publicKey == nil && len(opts.X5c) > 0 && opts.X5c[0] != nil {
+		publicKey = opts.X5c[0].PublicKey
+	}
+	if publicKey != nil {
+		if err := checkKeyMatchesAlg(publicKey, alg); err != nil {
+			return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+		}
+	}
+
+	sdAlg := opts.SdAlg
+	if sdAlg == "" {
+		sdAlg = "sha-256"
+	}
+	if _, err := GetHash(sdAlg); err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+	}
+
+	body, err := normaliseClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := opts.Validity.apply(body); err != nil {
+		return nil, err
+	}
+
+	if opts.Cnf != nil {
+		if _, ok := body["cnf"]; ok {
+			return nil, fmt.Errorf("%wclaims must not contain a cnf claim when Cnf is set", e.ErrInvalidIssuance)
+		}
+		if err := opts.Cnf.validate(); err != nil {
+			return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+		}
+		body["cnf"] = opts.Cnf.claim()
+	}
+
+	if opts.ValidateClaims != nil {
+		if err := opts.ValidateClaims(body); err != nil {
+			return nil, fmt.Errorf("%w%w", e.ErrInvalidIssuance, err)
+		}
+	}
+
+	policy, err := newDisclosurePolicy(opts.SelectivelyDisclosable)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := opts.DecoyDigests.validate(); err != nil {
+		return nil, err
+	}
+
+	salts := opts.SaltSource
+	if salts == nil {
+		salts = RandomSaltSource{}
+	}
+
+	b := &issuanceBuilder{sdAlg: sdAlg, salts: salts, decoys: opts.DecoyDigests}
+	if err := b.object(body, policy, nil); err != nil {
+		return nil, err
+	}
+
+	body["_sd_alg"] = sdAlg
+
+	head, err := buildHeader(opts, alg, publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+	}
+
+	b64Head, err := encodeSegment(head)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling sd-jwt header: %w", err)
+	}
+	b64Body, err := encodeSegment(body)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling sd-jwt body: %w", err)
+	}
+
+	return &PreparedIssuance{
+		SigningInput: b64Head + "." + b64Body,
+		Alg:          alg,
+		publicKey:    publicKey,
+		disclosures:  b.disclosures,
+	}, nil
+}
+
+// Finish completes the prepared SD-JWT with the provided signature over SigningInput.
+// The signature must use the JWS encoding for Alg, for ES algorithms this is the fixed length r || s encoding rather
+// than ASN.1 DER. When the public key of the issuer is known, the signature is also verified.
+func (p *PreparedIssuance) Finish(signature []byte) (*SdJwt, error) {
+	if err := checkSignature(p.publicKey, p.Alg, p.SigningInput, signature); err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+	}
+
+	token := fmt.Sprintf("%s.%s~", p.SigningInput, base64.RawURLEncoding.EncodeToString(signature))
+	for _, d := range p.disclosures {
+		token += d.EncodedValue + "~"
+	}
+
+	return New(token)
+}
+
+// normaliseClaims returns a deep copy of the provided claims in their JSON form (maps, slices and json.Number values)
+// so that typed values such as structs or []map[string]any slices can be traversed.
+// An error is returned if the claims contain any of the names reserved by the SD-JWT specification.
+func normaliseClaims(claims map[string]any) (map[string]any, error) {
+	b, err := json.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("%wfailed to marshal claims: %s", e.ErrInvalidIssuance, err.Error())
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var m map[string]any
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%wfailed to parse claims: %s", e.ErrInvalidIssuance, err.Error())
+	}
+	if m == nil {
+		m = map[string]any{}
+	}
+
+	if err := checkReservedClaims(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func checkReservedClaims(v any) error {
+	switch value := v.(type) {
+	case map[string]any:
+		for k, child := range value {
+			if k == "_sd" || k == "_sd_alg" || k == "..." {
+				return fmt.Errorf("%wclaims must not contain the reserved claim name %s", e.ErrInvalidIssuance, k)
+			}
+			if err := checkReservedClaims(child); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, child := range value {
+			if err := checkReservedClaims(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func encodeSegment(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,523 @@
+package go_sd_jwt_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func issuanceClaims() map[string]any {
+	return map[string]any{
+		"iss":         "https://issuer.example.com",
+		"sub":         "user_42",
+		"given_name":  "John",
+		"family_name": "Doe",
+		"birthdate":   "1940-01-01",
+		"address": map[string]any{
+			"street_address": "123 Main St",
+			"locality":       "Anytown",
+			"country":        "US",
+		},
+		"nationalities": []any{"US", "DE"},
+	}
+}
+
+// roundTrip returns the provided value as it would be seen after being parsed from JSON
+func roundTrip(t *testing.T, v any) map[string]any {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(b, &m))
+	return m
+}
+
+func TestIssue(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := issuanceClaims()
+	sdJwt, err := go_sd_jwt.Issue(claims, go_sd_jwt.IssuanceOptions{
+		Signer:                 signer,
+		Header:                 map[string]any{"typ": "example+sd-jwt"},
+		SelectivelyDisclosable: []string{"given_name", "family_name", "address"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ES256", sdJwt.Head["alg"])
+	assert.Equal(t, "example+sd-jwt", sdJwt.Head["typ"])
+	assert.Equal(t, "sha-256", sdJwt.Body["_sd_alg"])
+	assert.NotContains(t, sdJwt.Body, "given_name")
+	assert.NotContains(t, sdJwt.Body, "family_name")
+	assert.NotContains(t, sdJwt.Body, "address")
+	assert.Len(t, sdJwt.Body["_sd"], 3)
+	assert.Len(t, sdJwt.Disclosures, 3)
+	assert.NotEmpty(t, sdJwt.Signature)
+
+	assert.Contains(t, claims, "given_name", "the provided claims should not be modified")
+
+	require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: signer.Public()}))
+
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	require.NoError(t, err)
+	assert.Equal(t, roundTrip(t, claims), disclosed)
+
+	token, err := sdJwt.Token()
+	require.NoError(t, err)
+	parsed, err := go_sd_jwt.New(*token)
+	require.NoError(t, err)
+	require.NoError(t, parsed.Verify(go_sd_jwt.VerificationOptions{IssuerKey: signer.Public()}))
+}
+
+func TestIssue_Algorithms(t *testing.T) {
+	for _, alg := range []model.Algorithm{model.ES256, model.ES384, model.ES512, model.RS256, model.PS384} {
+		t.Run(alg.String(), func(t *testing.T) {
+			signer, err := jws.GetSigner(alg, nil)
+			require.NoError(t, err)
+
+			sdJwt, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+				Signer:                 signer,
+				SdAlg:                  "sha-512",
+				SelectivelyDisclosable: []string{"birthdate"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, alg.String(), sdJwt.Head["alg"])
+			assert.Equal(t, "sha-512", sdJwt.Body["_sd_alg"])
+			require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: signer.Public()}))
+
+			disclosed, err := sdJwt.GetDisclosedClaims()
+			require.NoError(t, err)
+			assert.Equal(t, "1940-01-01", disclosed["birthdate"])
+		})
+	}
+}
+
+func TestIssue_StandardLibrarySigner(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	sdJwt, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+		Signer:                 key,
+		Alg:                    "ES256",
+		SelectivelyDisclosable: []string{"sub"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: key.Public()}))
+}
+
+func TestIssue_Errors(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	stdKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		claims map[string]any
+		opts   go_sd_jwt.IssuanceOptions
+		err    string
+	}{
+		"no signer": {
+			claims: issuanceClaims(),
+			opts:   go_sd_jwt.IssuanceOptions{},
+			err:    "invalid issuance: a signer must be provided",
+		},
+		"no algorithm for a standard library signer": {
+			claims: issuanceClaims(),
+			opts:   go_sd_jwt.IssuanceOptions{Signer: stdKey},
+			err:    "invalid issuance: an algorithm must be specified when the signer does not declare one",
+		},
+		"algorithm does not match key": {
+			claims: issuanceClaims(),
+			opts:   go_sd_jwt.IssuanceOptions{Signer: signer, Alg: "ES384"},
+			err:    "invalid issuance: algorithm ES384 cannot be used with an ecdsa P-256 key",
+		},
+		"symmetric algorithm": {
+			claims: issuanceClaims(),
+			opts:   go_sd_jwt.IssuanceOptions{Signer: signer, Alg: "HS256"},
+			err:    "invalid issuance: unsupported signing algorithm: HS256",
+		},
+		"header alg does not match": {
+			claims: issuanceClaims(),
+			opts:   go_sd_jwt.IssuanceOptions{Signer: signer, Header: map[string]any{"alg": "RS256"}},
+			err:    "invalid issuance: header alg RS256 does not match signing algorithm ES256",
+		},
+		"unsupported sd alg": {
+			claims: issuanceClaims(),
+			opts:   go_sd_jwt.IssuanceOptions{Signer: signer, SdAlg: "md5"},
+			err:    "invalid issuance: unsupported _sd_alg: md5",
+		},
+		"missing claim": {
+			claims: issuanceClaims(),
+			opts:   go_sd_jwt.IssuanceOptions{Signer: signer, SelectivelyDisclosable: []string{"email"}},
+			err:    "invalid issuance: selectively disclosable claim email not found",
+		},
+		"duplicate claim": {
+			claims: issuanceClaims(),
+			opts:   go_sd_jwt.IssuanceOptions{Signer: signer, SelectivelyDisclosable: []string{"sub", "sub"}},
+			err:    "invalid issuance: claim path sub is listed as selectively disclosable more than once",
+		},
+		"reserved claim": {
+			claims: map[string]any{"address": map[string]any{"_sd": []any{}}},
+			opts:   go_sd_jwt.IssuanceOptions{Signer: signer},
+			err:    "invalid issuance: claims must not contain the reserved claim name _sd",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sdJwt, err := go_sd_jwt.Issue(tt.claims, tt.opts)
+			require.Error(t, err)
+			assert.Nil(t, sdJwt)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestIssue_ValidateClaims(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cnf, err := go_sd_jwt.NewJwkConfirmation(&holderKey.PublicKey)
+	require.NoError(t, err)
+
+	// the claims are validated after the iat, exp and cnf claims are added and before any are made selectively disclosable
+	var validated map[string]any
+	_, err = go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+		Signer:                 signer,
+		Cnf:                    cnf,
+		Validity:               go_sd_jwt.Validity{Lifetime: time.Hour},
+		SelectivelyDisclosable: []string{"given_name"},
+		ValidateClaims: func(claims map[string]any) error {
+			validated = maps.Clone(claims)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, validated, "iat")
+	assert.Contains(t, validated, "exp")
+	assert.Contains(t, validated, "cnf")
+	assert.Equal(t, "John", validated["given_name"])
+	assert.NotContains(t, validated, "_sd")
+
+	_, err = go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+		Signer: signer,
+		ValidateClaims: func(claims map[string]any) error {
+			return errors.New("claims are not allowed")
+		},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "invalid issuance: claims are not allowed", err.Error())
+}
+
+func TestIssue_ClaimPaths(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := issuanceClaims()
+	claims["evidence"] = []map[string]any{
+		{"type": "document", "document": map[string]any{"number": "53554554", "type": "idcard"}},
+	}
+
+	sdJwt, err := go_sd_jwt.Issue(claims, go_sd_jwt.IssuanceOptions{
+		Signer: signer,
+		SelectivelyDisclosable: []string{
+			"address.street_address",
+			"address.locality",
+			"nationalities[*]",
+			"evidence[0].document.number",
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, sdJwt.Disclosures, 5)
+
+	assert.NotContains(t, sdJwt.Body, "_sd")
+
+	address := sdJwt.Body["address"].(map[string]any)
+	assert.Equal(t, "US", address["country"])
+	assert.NotContains(t, address, "street_address")
+	assert.NotContains(t, address, "locality")
+	assert.Len(t, address["_sd"], 2)
+
+	nationalities := sdJwt.Body["nationalities"].([]any)
+	require.Len(t, nationalities, 2)
+	for _, n := range nationalities {
+		assert.Contains(t, n, "...")
+	}
+
+	document := sdJwt.Body["evidence"].([]any)[0].(map[string]any)["document"].(map[string]any)
+	assert.Equal(t, "idcard", document["type"])
+	assert.NotContains(t, document, "number")
+	assert.Len(t, document["_sd"], 1)
+
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	require.NoError(t, err)
+	assert.Equal(t, roundTrip(t, claims), disclosed)
+}
+
+func TestIssue_ClaimPathErrors(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		paths []string
+		err   string
+	}{
+		"invalid path": {
+			paths: []string{"address..locality"},
+			err:   "invalid issuance: invalid claim path address..locality: empty claim name",
+		},
+		"path starting with an index": {
+			paths: []string{"[0]"},
+			err:   "invalid issuance: claim path [0] must start with a claim name",
+		},
+		"missing nested claim": {
+			paths: []string{"address.region"},
+			err:   "invalid issuance: selectively disclosable claim address.region not found",
+		},
+		"object path into a string": {
+			paths: []string{"sub.value"},
+			err:   "invalid issuance: claim sub is not an object",
+		},
+		"array path into an object": {
+			paths: []string{"address[*]"},
+			err:   "invalid issuance: claim address is not an array",
+		},
+		"index out of range": {
+			paths: []string{"nationalities[2]"},
+			err:   "invalid issuance: selectively disclosable claim nationalities[2] not found",
+		},
+		"object and array paths for the same claim": {
+			paths: []string{"address.locality", "address[0]"},
+			err:   "invalid issuance: claim path address is used as both an object and an array",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{Signer: signer, SelectivelyDisclosable: tt.paths})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestIssue_ArrayElements(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := map[string]any{
+		"tags": []string{"x", "y", "z"},
+		"address_history": []map[string]any{
+			{"street_address": "1 First St", "locality": "Oldtown"},
+			{"street_address": "2 Second St", "locality": "Newtown"},
+		},
+		"matrix": [][]any{{"a", "b"}, {"c"}},
+	}
+
+	sdJwt, err := go_sd_jwt.Issue(claims, go_sd_jwt.IssuanceOptions{
+		Signer: signer,
+		SelectivelyDisclosable: []string{
+			"tags[1]",
+			"address_history[*]",
+			"address_history[*].street_address",
+			"matrix[*][*]",
+			"matrix[1]",
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, sdJwt.Disclosures, 1+2+2+3+1)
+
+	tags := sdJwt.Body["tags"].([]any)
+	require.Len(t, tags, 3)
+	assert.Equal(t, "x", tags[0])
+	assert.Contains(t, tags[1], "...")
+	assert.Equal(t, "z", tags[2])
+
+	history := sdJwt.Body["address_history"].([]any)
+	require.Len(t, history, 2)
+	for _, entry := range history {
+		assert.Len(t, entry, 1)
+		assert.Contains(t, entry, "...")
+	}
+
+	matrix := sdJwt.Body["matrix"].([]any)
+	require.Len(t, matrix, 2)
+	first := matrix[0].([]any)
+	require.Len(t, first, 2)
+	assert.Contains(t, first[0], "...")
+	assert.Contains(t, first[1], "...")
+	assert.Contains(t, matrix[1], "...")
+
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	require.NoError(t, err)
+	assert.Equal(t, roundTrip(t, claims), disclosed)
+
+	t.Run("elements can be presented individually", func(t *testing.T) {
+		selected, err := sdJwt.SelectDisclosures("address_history[1]", "matrix[0][1]")
+		require.NoError(t, err)
+		sdJwt.Disclosures = selected
+
+		disclosed, err := sdJwt.GetDisclosedClaims()
+		require.NoError(t, err)
+		assert.Equal(t, []any{map[string]any{"locality": "Newtown"}}, disclosed["address_history"])
+		assert.Equal(t, []any{[]any{"b"}}, disclosed["matrix"])
+		assert.Equal(t, []any{"x", "z"}, disclosed["tags"])
+	})
+}
+
+func TestIssue_ArrayElementErrors(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := map[string]any{
+		"mixed":  []any{map[string]any{"a": "b"}, "c"},
+		"nested": []any{[]any{"a"}, "b"},
+	}
+
+	tests := map[string]struct {
+		paths []string
+		err   string
+	}{
+		"member of a non-object element": {
+			paths: []string{"mixed[*].a"},
+			err:   "invalid issuance: claim mixed[1] is not an object",
+		},
+		"element of a non-array element": {
+			paths: []string{"nested[*][0]"},
+			err:   "invalid issuance: claim nested[1] is not an array",
+		},
+		"missing member of an element": {
+			paths: []string{"mixed[0].b"},
+			err:   "invalid issuance: selectively disclosable claim mixed[0].b not found",
+		},
+		"nested index out of range": {
+			paths: []string{"nested[0][1]"},
+			err:   "invalid issuance: selectively disclosable claim nested[0][1] not found",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := go_sd_jwt.Issue(claims, go_sd_jwt.IssuanceOptions{Signer: signer, SelectivelyDisclosable: tt.paths})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestPrepareIssuance(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// signs as a remote signing service would, returning the JWS r || s encoding
+	remoteSign := func(signingInput string) []byte {
+		digest := sha256.Sum256([]byte(signingInput))
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		require.NoError(t, err)
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig
+	}
+
+	tests := []struct {
+		name string
+		opts go_sd_jwt.IssuanceOptions
+	}{
+		{
+			name: "public key",
+			opts: go_sd_jwt.IssuanceOptions{Alg: "ES256", PublicKey: &key.PublicKey},
+		},
+		{
+			name: "certificate",
+			opts: go_sd_jwt.IssuanceOptions{Alg: "ES256", X5c: []*x509.Certificate{selfSignedCertificate(t, key)}},
+		},
+		{
+			name: "no key",
+			opts: go_sd_jwt.IssuanceOptions{Alg: "es256"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.SelectivelyDisclosable = []string{"given_name", "address"}
+			prepared, err := go_sd_jwt.PrepareIssuance(issuanceClaims(), tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, "ES256", prepared.Alg)
+
+			sdJwt, err := prepared.Finish(remoteSign(prepared.SigningInput))
+			require.NoError(t, err)
+			require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: &key.PublicKey}))
+			assert.Len(t, sdJwt.Disclosures, 2)
+
+			token, err := sdJwt.Token()
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(*token, prepared.SigningInput+"."))
+
+			disclosed, err := sdJwt.GetDisclosedClaims()
+			require.NoError(t, err)
+			assert.Equal(t, roundTrip(t, issuanceClaims()), disclosed)
+		})
+	}
+}
+
+func TestPrepareIssuance_Errors(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, err = go_sd_jwt.PrepareIssuance(issuanceClaims(), go_sd_jwt.IssuanceOptions{})
+	require.Error(t, err)
+	assert.Equal(t, "invalid issuance: an algorithm must be specified when the signer does not declare one", err.Error())
+
+	_, err = go_sd_jwt.PrepareIssuance(issuanceClaims(), go_sd_jwt.IssuanceOptions{Alg: "ES384", PublicKey: &key.PublicKey})
+	require.Error(t, err)
+	assert.Equal(t, "invalid issuance: algorithm ES384 cannot be used with an ecdsa P-256 key", err.Error())
+
+	prepared, err := go_sd_jwt.PrepareIssuance(issuanceClaims(), go_sd_jwt.IssuanceOptions{Alg: "ES256", PublicKey: &key.PublicKey})
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(prepared.SigningInput))
+
+	der, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	_, err = prepared.Finish(der)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid issuance: ES256 signatures must be the 64 byte r || s encoding, got")
+
+	_, err = prepared.Finish(nil)
+	require.Error(t, err)
+	assert.Equal(t, "invalid issuance: signature must not be empty", err.Error())
+
+	_, err = prepared.Finish(make([]byte, 64))
+	require.Error(t, err)
+	assert.Equal(t, "invalid issuance: signature is not valid for the signing input", err.Error())
+
+	rsaPrepared, err := go_sd_jwt.PrepareIssuance(issuanceClaims(), go_sd_jwt.IssuanceOptions{Alg: "RS256", PublicKey: &rsaKey.PublicKey})
+	require.NoError(t, err)
+	_, err = rsaPrepared.Finish(make([]byte, 128))
+	require.Error(t, err)
+	assert.Equal(t, "invalid issuance: RS256 signatures must be 256 bytes for the issuer key, got 128 bytes", err.Error())
+
+	rsaDigest := sha256.Sum256([]byte(rsaPrepared.SigningInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, rsaDigest[:])
+	require.NoError(t, err)
+	sdJwt, err := rsaPrepared.Finish(sig)
+	require.NoError(t, err)
+	require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: &rsaKey.PublicKey}))
+}
@@ -0,0 +1,263 @@
+package go_sd_jwt
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/utils"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/kbjwt"
+)
+
+// KeyBindingOptions configures the key binding jwt created by PrepareKeyBindingJwt.
+// Alg, Aud and Nonce are required.
+type KeyBindingOptions struct {
+	// Alg is the JWS algorithm the holder key signs with (e.g. ES256)
+	Alg string
+	// Aud is the intended verifier
+	Aud string
+	// Nonce is the nonce provided by the verifier
+	Nonce string
+	// IssuedAt is used for the iat claim, defaults to the current time
+	IssuedAt time.Time
+	// HolderKey is the public key of the holder. When the cnf claim contains a jkt, it is added to the kb-jwt header as
+	// a jwk. When set, Finish checks the signature against it.
+	HolderKey crypto.PublicKey
+	// VerifyHolderKey requires the SD-JWT to contain a valid cnf claim and checks HolderKey against it before signing.
+	// When HolderKey is not set, the key in a cnf.jwk claim is used to check the signature in Finish.
+	VerifyHolderKey bool
+}
+
+// SignKeyBindingJwt signs and adds a key binding jwt to the SD-JWT in one step, using PrepareKeyBindingJwt and Finish.
+// opts.HolderKey defaults to the public key of the signer.
+func (s *SdJwt) SignKeyBindingJwt(signer crypto.Signer, opts KeyBindingOptions) error {
+	if opts.HolderKey == nil {
+		opts.HolderKey = signer.Public()
+	}
+
+	prepared, err := s.PrepareKeyBindingJwt(opts)
+	if err != nil {
+		return err
+	}
+
+	sig, err := sign(signer, prepared.Alg, prepared.SigningInput)
+	if err != nil {
+		return fmt.Errorf("error signing kb-jwt: %w", err)
+	}
+
+	return prepared.Finish(sig)
+}
+
+// PreparedKeyBinding is a key binding jwt which has been built by PrepareKeyBindingJwt but not yet signed.
+// SigningInput is the exact JWS signing input which must be signed with the holder key using Alg, e.g. by a secure
+// element, before calling Finish.
+type PreparedKeyBinding struct {
+	SigningInput string
+	Alg          string
+
+	sdJwt     *SdJwt
+	kbJwt     kbjwt.KbJwt
+	sdHash    string
+	holderKey crypto.PublicKey
+}
+
+// PrepareKeyBindingJwt builds a key binding jwt for the SD-JWT with its current disclosures without signing it.
+// The sd_hash claim is calculated using the hash algorithm specified by the _sd_alg claim (sha-256 if not present).
+// The disclosures must not be changed between preparing and finishing the key binding jwt.
+func (s *SdJwt) PrepareKeyBindingJwt(opts KeyBindingOptions) (*PreparedKeyBinding, error) {
+	if s.KbJwt != nil {
+		return nil, errors.New("key binding jwt already exists")
+	}
+	if opts.Alg == "" {
+		return nil, errors.New("an algorithm must be specified for the key binding jwt")
+	}
+	if opts.Aud == "" {
+		return nil, errors.New("an audience must be specified for the key binding jwt")
+	}
+	if opts.Nonce == "" {
+		return nil, errors.New("a nonce must be specified for the key binding jwt")
+	}
+
+	alg := strings.ToUpper(opts.Alg)
+	if _, err := algToHash(alg); err != nil {
+		return nil, err
+	}
+
+	sdHash, err := s.calculateSdHash()
+	if err != nil {
+		return nil, err
+	}
+
+	kbHead := map[string]any{
+		"typ": "kb+jwt",
+		"alg": alg,
+	}
+
+	holderKey, err := s.keyBindingHolderKey(opts.HolderKey, kbHead, opts.VerifyHolderKey)
+	if err != nil {
+		return nil, err
+	}
+
+	iat := opts.IssuedAt
+	if iat.IsZero() {
+		iat = time.Now()
+	}
+
+	kbJwt := kbjwt.KbJwt{
+		Iat:    utils.Pointer(iat.Unix()),
+		Aud:    utils.Pointer(opts.Aud),
+		Nonce:  utils.Pointer(opts.Nonce),
+		SdHash: utils.Pointer(sdHash),
+	}
+
+	b64KbHead, err := encodeSegment(kbHead)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling kb-jwt header: %w", err)
+	}
+	b64KbBody, err := encodeSegment(kbJwt)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling kb-jwt body: %w", err)
+	}
+
+	return &PreparedKeyBinding{
+		SigningInput: b64KbHead + "." + b64KbBody,
+		Alg:          alg,
+		sdJwt:        s,
+		kbJwt:        kbJwt,
+		sdHash:       sdHash,
+		holderKey:    holderKey,
+	}, nil
+}
+
+// Finish adds the prepared key binding jwt, signed with the provided signature over SigningInput, to the SD-JWT.
+// The signature must use the JWS encoding for Alg, for ES algorithms this is the fixed length r || s encoding rather
+// than ASN.1 DER. When the holder key is known, from a cnf claim containing a jwk or jkt or from
+// KeyBindingOptions.HolderKey, the signature is also verified against that key.
+func (p *PreparedKeyBinding) Finish(signature []byte) error {
+	if p.sdJwt.KbJwt != nil {
+		return errors.New("key binding jwt already exists")
+	}
+
+	sdHash, err := p.sdJwt.calculateSdHash()
+	if err != nil {
+		return err
+	}
+	if sdHash != p.sdHash {
+		return errors.New("the sd-jwt has changed since the key binding jwt was prepared")
+	}
+
+	if err := checkSignature(p.holderKey, p.Alg, p.SigningInput, signature); err != nil {
+		return fmt.Errorf("invalid kb-jwt signature: %w", err)
+	}
+
+	kbJwt := p.kbJwt
+	kbJwt.Token = p.SigningInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+	p.sdJwt.KbJwt = &kbJwt
+	return nil
+}
+
+// keyBindingHolderKey returns the holder key the kb-jwt must be signed with, or nil if it is not known. When verify is
+// set, the key is checked against the cnf claim. For a jkt confirmation, the holder key is added to the kb-jwt header.
+func (s *SdJwt) keyBindingHolderKey(holderKey crypto.PublicKey, kbHead map[string]any, verify bool) (crypto.PublicKey, error) {
+	if !verify {
+		if cnf, ok := s.Body["cnf"].(map[string]any); ok && holderKey != nil {
+			if _, ok := cnf["jkt"]; ok {
+				holderJwk, err := publicJwk(holderKey)
+				if err != nil {
+					return nil, err
+				}
+				kbHead["jwk"] = holderJwk
+			}
+		}
+		return holderKey, nil
+	}
+
+	cnf, err := s.Confirmation()
+	if err != nil {
+		return nil, err
+	}
+	if cnf == nil {
+		return nil, errors.New("the sd-jwt has no 'cnf' claim to verify the holder key against")
+	}
+
+	switch {
+	case cnf.Jwk != nil:
+		cnfKey, err := publicKeyFromJwk(cnf.Jwk)
+		if err != nil {
+			return nil, err
+		}
+		if holderKey != nil && !publicKeysEqual(cnfKey, holderKey) {
+			return nil, errors.New("the holder key does not match the jwk in the 'cnf' claim")
+		}
+		return cnfKey, nil
+	case cnf.Jkt != "":
+		if holderKey == nil {
+			return nil, errors.New("a holder key must be provided when the 'cnf' claim contains a jkt")
+		}
+		holderJwk, err := publicJwk(holderKey)
+		if err != nil {
+			return nil, err
+		}
+		thumbprint, err := jwkThumbprint(holderJwk)
+		if err != nil {
+			return nil, err
+		}
+		if thumbprint != cnf.Jkt {
+			return nil, errors.New("the holder key does not match the jkt in the 'cnf' claim")
+		}
+		kbHead["jwk"] = holderJwk
+		return holderKey, nil
+	default:
+		return holderKey, nil
+	}
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	k, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(b)
+}
+
+// calculateSdHash returns the sd_hash for the SD-JWT in its current form, as serialised by Token without a kb-jwt
+func (s *SdJwt) calculateSdHash() (string, error) {
+	strAlg, _ := s.Body["_sd_alg"].(string)
+	h, err := GetHash(strAlg)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := s.serialise()
+	if err != nil {
+		return "", err
+	}
+	h.Write([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// serialise returns the issuer-signed jwt and disclosures in their token format, without any kb-jwt
+func (s *SdJwt) serialise() (string, error) {
+	headBytes, err := json.Marshal(s.Head)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling sd-jwt header: %w", err)
+	}
+	bodyBytes, err := json.Marshal(s.Body)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling sd-jwt body: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(base64.RawURLEncoding.EncodeToString(headBytes))
+	sb.WriteString(".")
+	sb.WriteString(base64.RawURLEncoding.EncodeToString(bodyBytes))
+	sb.WriteString(".")
+	sb.WriteString(s.Signature)
+	sb.WriteString("~")
+	for _, d := range s.Disclosures {
+		sb.WriteString(d.EncodedValue)
+		sb.WriteString("~")
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,217 @@
+package go_sd_jwt_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MichaelFraser99/go-jose/jwk"
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/disclosure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issueWithHolderKey issues an SD-JWT bound to the provided holder key through a cnf.jwk claim
+func issueWithHolderKey(t *testing.T, holderKey *ecdsa.PrivateKey) *go_sd_jwt.SdJwt {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	holderJwk, err := jwk.PublicJwk(&holderKey.PublicKey)
+	require.NoError(t, err)
+
+	claims := issuanceClaims()
+	claims["cnf"] = map[string]any{"jwk": *holderJwk}
+	sdJwt, err := go_sd_jwt.Issue(claims, go_sd_jwt.IssuanceOptions{
+		Signer:                 issuerSigner,
+		SelectivelyDisclosable: []string{"given_name", "family_name"},
+	})
+	require.NoError(t, err)
+	return sdJwt
+}
+
+// secureElementSign signs as a secure element would, returning the JWS r || s encoding
+func secureElementSign(t *testing.T, key *ecdsa.PrivateKey, signingInput string) []byte {
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return sig
+}
+
+func TestPrepareKeyBindingJwt(t *testing.T) {
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	sdJwt := issueWithHolderKey(t, holderKey)
+
+	iat := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	prepared, err := sdJwt.PrepareKeyBindingJwt(go_sd_jwt.KeyBindingOptions{
+		Alg:      "es256",
+		Aud:      "https://verifier.example.com",
+		Nonce:    "nonce-1",
+		IssuedAt: iat,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ES256", prepared.Alg)
+	assert.Nil(t, sdJwt.KbJwt, "the kb-jwt should not be added until it is signed")
+
+	require.NoError(t, prepared.Finish(secureElementSign(t, holderKey, prepared.SigningInput)))
+	require.NotNil(t, sdJwt.KbJwt)
+	assert.Equal(t, iat.Unix(), *sdJwt.KbJwt.Iat)
+	assert.Equal(t, "https://verifier.example.com", *sdJwt.KbJwt.Aud)
+	assert.Equal(t, "nonce-1", *sdJwt.KbJwt.Nonce)
+
+	token, err := sdJwt.Token()
+	require.NoError(t, err)
+	presented, err := go_sd_jwt.New(*token)
+	require.NoError(t, err)
+
+	aud, nonce := "https://verifier.example.com", "nonce-1"
+	require.NoError(t, presented.Verify(go_sd_jwt.VerificationOptions{
+		ExpectedAudience:     &aud,
+		ExpectedNonce:        &nonce,
+		VerifyKBJwtSignature: true,
+	}))
+}
+
+func TestPrepareKeyBindingJwt_Errors(t *testing.T) {
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	options := go_sd_jwt.KeyBindingOptions{Alg: "ES256", Aud: "https://verifier.example.com", Nonce: "nonce-1"}
+
+	tests := []struct {
+		name string
+		opts go_sd_jwt.KeyBindingOptions
+		err  string
+	}{
+		{
+			name: "missing alg",
+			opts: go_sd_jwt.KeyBindingOptions{Aud: "https://verifier.example.com", Nonce: "nonce-1"},
+			err:  "an algorithm must be specified for the key binding jwt",
+		},
+		{
+			name: "missing aud",
+			opts: go_sd_jwt.KeyBindingOptions{Alg: "ES256", Nonce: "nonce-1"},
+			err:  "an audience must be specified for the key binding jwt",
+		},
+		{
+			name: "missing nonce",
+			opts: go_sd_jwt.KeyBindingOptions{Alg: "ES256", Aud: "https://verifier.example.com"},
+			err:  "a nonce must be specified for the key binding jwt",
+		},
+		{
+			name: "unsupported alg",
+			opts: go_sd_jwt.KeyBindingOptions{Alg: "HS256", Aud: "https://verifier.example.com", Nonce: "nonce-1"},
+			err:  "unsupported signing algorithm: HS256",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := issueWithHolderKey(t, holderKey).PrepareKeyBindingJwt(tt.opts)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+
+	t.Run("der signature", func(t *testing.T) {
+		prepared, err := issueWithHolderKey(t, holderKey).PrepareKeyBindingJwt(options)
+		require.NoError(t, err)
+		digest := sha256.Sum256([]byte(prepared.SigningInput))
+		der, err := ecdsa.SignASN1(rand.Reader, holderKey, digest[:])
+		require.NoError(t, err)
+
+		err = prepared.Finish(der)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid kb-jwt signature: ES256 signatures must be the 64 byte r || s encoding")
+	})
+
+	t.Run("signed with a key other than cnf", func(t *testing.T) {
+		verified := options
+		verified.VerifyHolderKey = true
+		prepared, err := issueWithHolderKey(t, holderKey).PrepareKeyBindingJwt(verified)
+		require.NoError(t, err)
+
+		err = prepared.Finish(secureElementSign(t, otherKey, prepared.SigningInput))
+		require.Error(t, err)
+		assert.Equal(t, "invalid kb-jwt signature: signature is not valid for the signing input", err.Error())
+	})
+
+	t.Run("disclosures changed", func(t *testing.T) {
+		sdJwt := issueWithHolderKey(t, holderKey)
+		prepared, err := sdJwt.PrepareKeyBindingJwt(options)
+		require.NoError(t, err)
+
+		sdJwt.Disclosures = []disclosure.Disclosure{sdJwt.Disclosures[0]}
+		err = prepared.Finish(secureElementSign(t, holderKey, prepared.SigningInput))
+		require.Error(t, err)
+		assert.Equal(t, "the sd-jwt has changed since the key binding jwt was prepared", err.Error())
+	})
+
+	t.Run("already bound", func(t *testing.T) {
+		sdJwt := issueWithHolderKey(t, holderKey)
+		first, err := sdJwt.PrepareKeyBindingJwt(options)
+		require.NoError(t, err)
+		second, err := sdJwt.PrepareKeyBindingJwt(options)
+		require.NoError(t, err)
+
+		require.NoError(t, first.Finish(secureElementSign(t, holderKey, first.SigningInput)))
+		err = second.Finish(secureElementSign(t, holderKey, second.SigningInput))
+		require.Error(t, err)
+		assert.Equal(t, "key binding jwt already exists", err.Error())
+
+		_, err = sdJwt.PrepareKeyBindingJwt(options)
+		require.Error(t, err)
+		assert.Equal(t, "key binding jwt already exists", err.Error())
+	})
+}
+
+func TestSignKeyBindingJwt(t *testing.T) {
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	sdJwt := issueWithHolderKey(t, holderKey)
+
+	require.NoError(t, sdJwt.SignKeyBindingJwt(holderKey, go_sd_jwt.KeyBindingOptions{
+		Alg:   "ES256",
+		Aud:   "https://verifier.example.com",
+		Nonce: "nonce-1",
+	}))
+	require.NotNil(t, sdJwt.KbJwt)
+	assert.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{VerifyKBJwtSignature: true}))
+}
+
+func TestAddKeyBindingJwt_Compatibility(t *testing.T) {
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// AddKeyBindingJwt accepts an empty aud and nonce, any alg and a signer other than the one in the cnf claim
+	sdJwt := issueWithHolderKey(t, holderKey)
+	require.NoError(t, sdJwt.AddKeyBindingJwt(otherKey, crypto.SHA256, "EdDSA", "", ""))
+	require.NotNil(t, sdJwt.KbJwt)
+	assert.Equal(t, "", *sdJwt.KbJwt.Aud)
+	assert.Equal(t, "", *sdJwt.KbJwt.Nonce)
+
+	head, err := base64.RawURLEncoding.DecodeString(strings.Split(sdJwt.KbJwt.Token, ".")[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"typ":"kb+jwt","alg":"EDDSA"}`, string(head))
+
+	// the provided hash must still match _sd_alg
+	sdJwt = issueWithHolderKey(t, holderKey)
+	err = sdJwt.AddKeyBindingJwt(holderKey, crypto.SHA384, "ES256", "https://verifier.example.com", "nonce-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "key binding jwt hashing algorithm does not match")
+}
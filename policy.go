@@ -0,0 +1,262 @@
+package go_sd_jwt
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"slices"
+
+	"github.com/MichaelFraser99/go-sd-jwt/v2/disclosure"
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+)
+
+// policyNode is a single level of a disclosure policy built from a set of claim paths.
+// sd marks the claim or element itself as selectively disclosable, members, elements and all hold the policy for the
+// object members, specific array elements and every array element beneath it respectively.
+type policyNode struct {
+	sd       bool
+	members  map[string]*policyNode
+	elements map[int]*policyNode
+	all      *policyNode
+}
+
+func newDisclosurePolicy(paths []string) (*policyNode, error) {
+	root := &policyNode{}
+	for _, path := range paths {
+		claimPath, err := ParseClaimPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+		}
+		if err := root.add(claimPath); err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+func (n *policyNode) add(path ClaimPath) error {
+	if _, ok := path[0].(string); !ok {
+		return fmt.Errorf("%wclaim path %s must start with a claim name", e.ErrInvalidIssuance, path)
+	}
+
+	current := n
+	for i, element := range path {
+		switch v := element.(type) {
+		case string:
+			if current.elements != nil || current.all != nil {
+				return fmt.Errorf("%wclaim path %s is used as both an object and an array", e.ErrInvalidIssuance, path[:i])
+			}
+			if current.members == nil {
+				current.members = map[string]*policyNode{}
+			}
+			if current.members[v] == nil {
+				current.members[v] = &policyNode{}
+			}
+			current = current.members[v]
+		case int:
+			if current.members != nil {
+				return fmt.Errorf("%wclaim path %s is used as both an object and an array", e.ErrInvalidIssuance, path[:i])
+			}
+			if current.elements == nil {
+				current.elements = map[int]*policyNode{}
+			}
+			if current.elements[v] == nil {
+				current.elements[v] = &policyNode{}
+			}
+			current = current.elements[v]
+		case nil:
+			if current.members != nil {
+				return fmt.Errorf("%wclaim path %s is used as both an object and an array", e.ErrInvalidIssuance, path[:i])
+			}
+			if current.all == nil {
+				current.all = &policyNode{}
+			}
+			current = current.all
+		}
+	}
+
+	if current.sd {
+		return fmt.Errorf("%wclaim path %s is listed as selectively disclosable more than once", e.ErrInvalidIssuance, path)
+	}
+	current.sd = true
+	return nil
+}
+
+// mergePolicyNodes combines the policy for a specific array element with the policy for every element of the array
+func mergePolicyNodes(a, b *policyNode) *policyNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	merged := &policyNode{sd: a.sd || b.sd}
+	for _, n := range []*policyNode{a, b} {
+		for k, v := range n.members {
+			if merged.members == nil {
+				merged.members = map[string]*policyNode{}
+			}
+			merged.members[k] = mergePolicyNodes(merged.members[k], v)
+		}
+		for k, v := range n.elements {
+			if merged.elements == nil {
+				merged.elements = map[int]*policyNode{}
+			}
+			merged.elements[k] = mergePolicyNodes(merged.elements[k], v)
+		}
+		merged.all = mergePolicyNodes(merged.all, n.all)
+	}
+	return merged
+}
+
+// issuanceBuilder applies a disclosure policy to a set of claims, collecting the disclosures created along the way
+type issuanceBuilder struct {
+	sdAlg       string
+	salts       SaltSource
+	decoys      DecoyDigests
+	disclosures []disclosure.Disclosure
+}
+
+func (b *issuanceBuilder) digest(d *disclosure.Disclosure) (string, error) {
+	h, err := GetHash(b.sdAlg)
+	if err != nil {
+		return "", err
+	}
+	return string(d.Hash(h)), nil
+}
+
+func hashString(h hash.Hash, value string) string {
+	h.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// value applies the provided policy to the contents of the provided value. Nested values are processed before their
+// parents so a selectively disclosable object or array may itself contain digests (recursive disclosures).
+func (b *issuanceBuilder) value(v any, node *policyNode, path ClaimPath) (any, error) {
+	switch {
+	case node.members != nil:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%wclaim %s is not an object", e.ErrInvalidIssuance, path)
+		}
+		if err := b.object(obj, node, path); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case node.elements != nil || node.all != nil:
+		arr, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%wclaim %s is not an array", e.ErrInvalidIssuance, path)
+		}
+		return b.array(arr, node, path)
+	default:
+		return v, nil
+	}
+}
+
+func (b *issuanceBuilder) object(obj map[string]any, node *policyNode, path ClaimPath) error {
+	keys := make([]string, 0, len(node.members))
+	for k := range node.members {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var digests []string
+	for _, key := range keys {
+		child := node.members[key]
+		claimPath := path.Append(key)
+
+		value, ok := obj[key]
+		if !ok {
+			return fmt.Errorf("%wselectively disclosable claim %s not found", e.ErrInvalidIssuance, claimPath)
+		}
+
+		value, err := b.value(value, child, claimPath)
+		if err != nil {
+			return err
+		}
+
+		if !child.sd {
+			obj[key] = value
+			continue
+		}
+
+		salt, err := b.salts.NewSalt()
+		if err != nil {
+			return fmt.Errorf("error generating salt: %w", err)
+		}
+		d, err := disclosure.NewFromObject(key, value, &salt)
+		if err != nil {
+			return fmt.Errorf("%wfailed to create disclosure for claim %s: %s", e.ErrInvalidIssuance, claimPath, err.Error())
+		}
+		digest, err := b.digest(d)
+		if err != nil {
+			return err
+		}
+		digests = append(digests, digest)
+		b.disclosures = append(b.disclosures, *d)
+		delete(obj, key)
+	}
+
+	if len(digests) > 0 {
+		decoys, err := b.decoyDigests()
+		if err != nil {
+			return err
+		}
+		digests = append(digests, decoys...)
+
+		// sorting the digests hides the original order of the claims
+		slices.Sort(digests)
+		obj["_sd"] = digests
+	}
+	return nil
+}
+
+func (b *issuanceBuilder) array(arr []any, node *policyNode, path ClaimPath) ([]any, error) {
+	for index := range node.elements {
+		if index >= len(arr) {
+			return nil, fmt.Errorf("%wselectively disclosable claim %s not found", e.ErrInvalidIssuance, path.Append(index))
+		}
+	}
+
+	hasDigests := false
+	for i := range arr {
+		child := mergePolicyNodes(node.elements[i], node.all)
+		if child == nil {
+			continue
+		}
+		claimPath := path.Append(i)
+
+		value, err := b.value(arr[i], child, claimPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if !child.sd {
+			arr[i] = value
+			continue
+		}
+
+		salt, err := b.salts.NewSalt()
+		if err != nil {
+			return nil, fmt.Errorf("error generating salt: %w", err)
+		}
+		d, err := disclosure.NewFromArrayElement(value, &salt)
+		if err != nil {
+			return nil, fmt.Errorf("%wfailed to create disclosure for claim %s: %s", e.ErrInvalidIssuance, claimPath, err.Error())
+		}
+		digest, err := b.digest(d)
+		if err != nil {
+			return nil, err
+		}
+		b.disclosures = append(b.disclosures, *d)
+		arr[i] = map[string]any{"...": digest}
+		hasDigests = true
+	}
+
+	if hasDigests {
+		return b.insertArrayDecoys(arr)
+	}
+	return arr, nil
+}
@@ -0,0 +1,123 @@
+package go_sd_jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	s "github.com/MichaelFraser99/go-sd-jwt/v2/internal/salt"
+)
+
+// SaltSource provides the salts used when creating disclosures and decoy digests during issuance.
+// Implementations must be safe for concurrent use.
+type SaltSource interface {
+	NewSalt() (string, error)
+}
+
+// RandomSaltSource generates salts from Length cryptographically secure random bytes. Length defaults to 16 bytes
+// (128 bits) and must not be less than this.
+// This is the default SaltSource and the only one which should be used outside of tests.
+type RandomSaltSource struct {
+	Length int
+}
+
+func (r RandomSaltSource) NewSalt() (string, error) {
+	length := r.Length
+	if length == 0 {
+		length = s.DefaultLength
+	}
+	if length < s.DefaultLength {
+		return "", fmt.Errorf("salt length must be at least %d bytes", s.DefaultLength)
+	}
+	return s.New(length)
+}
+
+// DeterministicSaltSource generates a reproducible sequence of salts derived from a seed, so that issued tokens can be
+// compared byte for byte against golden test vectors. The salts are not secret to anyone who knows the seed, so it
+// must not be used in production.
+type DeterministicSaltSource struct {
+	seed   []byte
+	length int
+
+	mu      sync.Mutex
+	counter uint64
+}
+
+// NewDeterministicSaltSource returns a DeterministicSaltSource producing salts of length bytes derived from the seed,
+// a length of 0 selects the default of 16 bytes
+func NewDeterministicSaltSource(seed []byte, length int) (*DeterministicSaltSource, error) {
+	if len(seed) == 0 {
+		return nil, errors.New("a seed must be provided")
+	}
+	if length < 0 {
+		return nil, errors.New("salt length must not be negative")
+	}
+	if length == 0 {
+		length = s.DefaultLength
+	}
+	return &DeterministicSaltSource{seed: append([]byte{}, seed...), length: length}, nil
+}
+
+// NewSalt returns the next salt in the sequence, the nth salt is the first length bytes of
+// HMAC-SHA256(seed, n || 0) || HMAC-SHA256(seed, n || 1) || ... with n and the block number as 64-bit big-endian integers
+func (d *DeterministicSaltSource) NewSalt() (string, error) {
+	d.mu.Lock()
+	n := d.counter
+	d.counter++
+	d.mu.Unlock()
+
+	out := make([]byte, 0, d.length+sha256.Size)
+	for block := uint64(0); len(out) < d.length; block++ {
+		mac := hmac.New(sha256.New, d.seed)
+		mac.Write(binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64(nil, n), block))
+		out = mac.Sum(out)
+	}
+	return base64.RawURLEncoding.EncodeToString(out[:d.length]), nil
+}
+
+// CounterSaltSource generates salts from an incrementing counter, for known-answer tests where the salt of every
+// disclosure must be predictable. Each salt is the base64url encoding of the counter as a big-endian integer of length
+// bytes. It must not be used in production.
+type CounterSaltSource struct {
+	length int
+
+	mu   sync.Mutex
+	next uint64
+}
+
+// NewCounterSaltSource returns a CounterSaltSource whose first salt encodes start, a length of 0 selects the default of
+// 16 bytes
+func NewCounterSaltSource(start uint64, length int) (*CounterSaltSource, error) {
+	if length < 0 {
+		return nil, errors.New("salt length must not be negative")
+	}
+	if length == 0 {
+		length = s.DefaultLength
+	}
+	if length < 8 && start>>(8*length) != 0 {
+		return nil, fmt.Errorf("start value %d does not fit in %d bytes", start, length)
+	}
+	return &CounterSaltSource{length: length, next: start}, nil
+}
+
+func (c *CounterSaltSource) NewSalt() (string, error) {
+	c.mu.Lock()
+	n := c.next
+	c.next++
+	c.mu.Unlock()
+
+	if c.length < 8 && n>>(8*c.length) != 0 {
+		return "", fmt.Errorf("counter value %d does not fit in %d bytes", n, c.length)
+	}
+
+	out := make([]byte, c.length)
+	for i := c.length - 1; i >= 0 && n > 0; i-- {
+		out[i] = byte(n)
+		n >>= 8
+	}
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
@@ -0,0 +1,154 @@
+package go_sd_jwt_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"testing"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomSaltSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		bytes  int
+	}{
+		{name: "default length", length: 0, bytes: 16},
+		{name: "custom length", length: 32, bytes: 32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := go_sd_jwt.RandomSaltSource{Length: tt.length}
+			first, err := source.NewSalt()
+			require.NoError(t, err)
+			second, err := source.NewSalt()
+			require.NoError(t, err)
+
+			decoded, err := base64.RawURLEncoding.DecodeString(first)
+			require.NoError(t, err)
+			assert.Len(t, decoded, tt.bytes)
+			assert.NotEqual(t, first, second)
+		})
+	}
+
+	_, err := go_sd_jwt.RandomSaltSource{Length: 8}.NewSalt()
+	require.Error(t, err)
+	assert.Equal(t, "salt length must be at least 16 bytes", err.Error())
+}
+
+func TestDeterministicSaltSource(t *testing.T) {
+	salts := func(seed string, length int) []string {
+		source, err := go_sd_jwt.NewDeterministicSaltSource([]byte(seed), length)
+		require.NoError(t, err)
+		result := make([]string, 3)
+		for i := range result {
+			result[i], err = source.NewSalt()
+			require.NoError(t, err)
+		}
+		return result
+	}
+
+	first := salts("seed", 0)
+	assert.Equal(t, first, salts("seed", 0), "the same seed should produce the same salts")
+	assert.NotEqual(t, first, salts("other seed", 0))
+	assert.NotEqual(t, first[0], first[1])
+
+	decoded, err := base64.RawURLEncoding.DecodeString(first[0])
+	require.NoError(t, err)
+	assert.Len(t, decoded, 16)
+
+	long := salts("seed", 48)
+	decoded, err = base64.RawURLEncoding.DecodeString(long[0])
+	require.NoError(t, err)
+	assert.Len(t, decoded, 48)
+	assert.Equal(t, first[0], base64.RawURLEncoding.EncodeToString(decoded[:16]), "longer salts should extend the same sequence")
+
+	_, err = go_sd_jwt.NewDeterministicSaltSource(nil, 0)
+	require.Error(t, err)
+	assert.Equal(t, "a seed must be provided", err.Error())
+
+	_, err = go_sd_jwt.NewDeterministicSaltSource([]byte("seed"), -1)
+	require.Error(t, err)
+	assert.Equal(t, "salt length must not be negative", err.Error())
+}
+
+func TestCounterSaltSource(t *testing.T) {
+	source, err := go_sd_jwt.NewCounterSaltSource(255, 0)
+	require.NoError(t, err)
+
+	first, err := source.NewSalt()
+	require.NoError(t, err)
+	second, err := source.NewSalt()
+	require.NoError(t, err)
+	assert.Equal(t, "AAAAAAAAAAAAAAAAAAAA_w", first)
+	assert.Equal(t, "AAAAAAAAAAAAAAAAAAABAA", second)
+
+	small, err := go_sd_jwt.NewCounterSaltSource(255, 1)
+	require.NoError(t, err)
+	salt, err := small.NewSalt()
+	require.NoError(t, err)
+	assert.Equal(t, "_w", salt)
+	_, err = small.NewSalt()
+	require.Error(t, err)
+	assert.Equal(t, "counter value 256 does not fit in 1 bytes", err.Error())
+
+	_, err = go_sd_jwt.NewCounterSaltSource(256, 1)
+	require.Error(t, err)
+	assert.Equal(t, "start value 256 does not fit in 1 bytes", err.Error())
+}
+
+func TestIssue_SaltSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	// RS256 signatures are deterministic so the whole token can be compared
+	issue := func(source go_sd_jwt.SaltSource) string {
+		sdJwt, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+			Signer:                 key,
+			Alg:                    "RS256",
+			SelectivelyDisclosable: []string{"given_name", "address.country", "address", "nationalities[*]"},
+			DecoyDigests:           go_sd_jwt.DecoyDigests{Min: 1, Max: 4},
+			SaltSource:             source,
+		})
+		require.NoError(t, err)
+		require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: &key.PublicKey}))
+		token, err := sdJwt.Token()
+		require.NoError(t, err)
+		return *token
+	}
+
+	deterministic := func() go_sd_jwt.SaltSource {
+		source, err := go_sd_jwt.NewDeterministicSaltSource([]byte("golden vectors"), 0)
+		require.NoError(t, err)
+		return source
+	}
+	assert.Equal(t, issue(deterministic()), issue(deterministic()))
+	assert.NotEqual(t, issue(go_sd_jwt.RandomSaltSource{}), issue(go_sd_jwt.RandomSaltSource{}))
+
+	counter, err := go_sd_jwt.NewCounterSaltSource(0, 0)
+	require.NoError(t, err)
+	sdJwt, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+		Signer:                 key,
+		Alg:                    "RS256",
+		SelectivelyDisclosable: []string{"family_name", "given_name"},
+		SaltSource:             counter,
+	})
+	require.NoError(t, err)
+	require.Len(t, sdJwt.Disclosures, 2)
+	assert.Equal(t, "AAAAAAAAAAAAAAAAAAAAAA", sdJwt.Disclosures[0].Salt)
+	assert.Equal(t, "family_name", *sdJwt.Disclosures[0].Key)
+	assert.Equal(t, "AAAAAAAAAAAAAAAAAAAAAQ", sdJwt.Disclosures[1].Salt)
+
+	_, err = go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+		Signer:                 key,
+		Alg:                    "RS256",
+		SelectivelyDisclosable: []string{"given_name"},
+		SaltSource:             go_sd_jwt.RandomSaltSource{Length: 4},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "error generating salt: salt length must be at least 16 bytes", err.Error())
+}
@@ -64,23 +64,10 @@ func NewFromComponents(protected, payload, signature string, disclosures []strin
 
 // Token This method returns the SD Jwt in its current state, in a token format, as a string
 func (s *SdJwt) Token() (*string, error) {
-	headBytes, err := json.Marshal(s.Head)
+	tokenString, err := s.serialise()
 	if err != nil {
 		return nil, err
 	}
-	b64Head := base64.RawURLEncoding.EncodeToString(headBytes)
-	bodyBytes, err := json.Marshal(s.Body)
-	if err != nil {
-		return nil, err
-	}
-	b64Body := base64.RawURLEncoding.EncodeToString(bodyBytes)
-
-	disclosureString := ""
-	for _, d := range s.Disclosures {
-		disclosureString += fmt.Sprintf("%s~", d.EncodedValue)
-	}
-
-	tokenString := fmt.Sprintf("%s.%s.%s~%s", b64Head, b64Body, s.Signature, disclosureString)
 
 	if s.KbJwt != nil {
 		tokenString += s.KbJwt.Token
@@ -92,6 +79,8 @@ func (s *SdJwt) Token() (*string, error) {
 // AddKeyBindingJwt This method adds a keybinding jwt signed with the provided signer interface and hash
 // If the provided hash does not match the hash algorithm specified in the SD Jwt (or isn't sha256 if no _sd_alg claim present), an error will be thrown
 // The sd_hash value will be set based off of all disclosures present in the current sd jwt object
+// To check the holder key against the cnf claim, or to sign the keybinding jwt outside the process (e.g. with a secure
+// element), see SignKeyBindingJwt and PrepareKeyBindingJwt
 func (s *SdJwt) AddKeyBindingJwt(signer crypto.Signer, h crypto.Hash, alg, aud, nonce string) error {
 	if s.KbJwt != nil {
 		return errors.New("key binding jwt already exists")
@@ -112,57 +101,31 @@ func (s *SdJwt) AddKeyBindingJwt(signer crypto.Signer, h crypto.Hash, alg, aud,
 	}
 
 	// calculate sd hash
-	bSdHead, err := json.Marshal(s.Head)
-	if err != nil {
-		return fmt.Errorf("error marshalling sd-jwt header: %w", err)
-	}
-	b64SdHead := make([]byte, base64.RawURLEncoding.EncodedLen(len(bSdHead)))
-	base64.RawURLEncoding.Encode(b64SdHead, bSdHead)
-
-	bSdBody, err := json.Marshal(s.Body)
+	fullToken, err := s.serialise()
 	if err != nil {
-		return fmt.Errorf("error marshalling sd-jwt body: %w", err)
-	}
-	b64SdBody := make([]byte, base64.RawURLEncoding.EncodedLen(len(bSdBody)))
-	base64.RawURLEncoding.Encode(b64SdBody, bSdBody)
-
-	disclosureString := ""
-	for _, d := range s.Disclosures {
-		disclosureString += d.EncodedValue + "~"
+		return err
 	}
-
-	fullToken := fmt.Sprintf("%s.%s.%s~%s", string(b64SdHead), string(b64SdBody), s.Signature, disclosureString)
 	hasher := h.New()
 	hasher.Write([]byte(fullToken))
-	hashedToken := hasher.Sum(nil)
-
-	b64SdHash := make([]byte, base64.RawURLEncoding.EncodedLen(len(hashedToken)))
-	base64.RawURLEncoding.Encode(b64SdHash, hashedToken)
+	sdHash := base64.RawURLEncoding.EncodeToString(hasher.Sum(nil))
 
 	kbJwt := kbjwt.KbJwt{
 		Iat:    utils.Pointer(time.Now().Unix()),
 		Aud:    utils.Pointer(aud),
 		Nonce:  utils.Pointer(nonce),
-		SdHash: utils.Pointer(string(b64SdHash)),
+		SdHash: utils.Pointer(sdHash),
 	}
 
-	bKbHead, err := json.Marshal(kbHead)
+	b64KbHead, err := encodeSegment(kbHead)
 	if err != nil {
 		return fmt.Errorf("error marshalling kb-jwt header: %w", err)
 	}
-
-	b64KbHead := make([]byte, base64.RawURLEncoding.EncodedLen(len(bKbHead)))
-	base64.RawURLEncoding.Encode(b64KbHead, bKbHead)
-
-	bKbBody, err := json.Marshal(kbJwt)
+	b64KbBody, err := encodeSegment(kbJwt)
 	if err != nil {
 		return fmt.Errorf("error marshalling kb-jwt body: %w", err)
 	}
 
-	b64KbBody := make([]byte, base64.RawURLEncoding.EncodedLen(len(bKbBody)))
-	base64.RawURLEncoding.Encode(b64KbBody, bKbBody)
-
-	signInput := string(b64KbHead) + "." + string(b64KbBody)
+	signInput := b64KbHead + "." + b64KbBody
 
 	signHasher := h.New()
 	signHasher.Write([]byte(signInput))
@@ -173,10 +136,7 @@ func (s *SdJwt) AddKeyBindingJwt(signer crypto.Signer, h crypto.Hash, alg, aud,
 		return fmt.Errorf("error signing kb-jwt: %w", err)
 	}
 
-	b64Sig := make([]byte, base64.RawURLEncoding.EncodedLen(len(sig)))
-	base64.RawURLEncoding.Encode(b64Sig, sig)
-
-	kbJwt.Token = signInput + "." + string(b64Sig)
+	kbJwt.Token = signInput + "." + base64.RawURLEncoding.EncodeToString(sig)
 
 	s.KbJwt = &kbJwt
 	return nil
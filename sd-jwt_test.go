@@ -3,6 +3,7 @@ package go_sd_jwt_test
 import (
 	"crypto"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"os"
@@ -928,3 +929,22 @@ func TestSDJwtWithNullValues(t *testing.T) {
 		t.Error("null field should remain nil")
 	}
 }
+
+func TestGetDisclosedClaims_ObjectDisclosureForArrayElement(t *testing.T) {
+	d, err := disclosure.NewFromObject("nationality", "US", nil)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(map[string]any{
+		"nationalities": []any{map[string]any{"...": string(d.Hash(sha256.New()))}},
+		"_sd_alg":       "sha-256",
+	})
+	require.NoError(t, err)
+
+	token := "eyJhbGciOiJFUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(body) + ".fakesig~" + d.EncodedValue + "~"
+	sdJwt, err := go_sd_jwt.New(token)
+	require.NoError(t, err)
+
+	_, err = sdJwt.GetDisclosedClaims()
+	require.Error(t, err)
+	assert.Equal(t, "invalid disclosure format for array element", err.Error())
+}
@@ -0,0 +1,160 @@
+package sdjwtvc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+)
+
+// DefaultAgeThresholds returns the age_equal_or_over thresholds of the EUDI PID rulebook
+func DefaultAgeThresholds() []int {
+	return []int{12, 14, 16, 18, 21, 65}
+}
+
+// AgeOptions configures the claims created by AgeClaims
+type AgeOptions struct {
+	// Thresholds are the ages added to age_equal_or_over, DefaultAgeThresholds() when empty
+	Thresholds []int
+	// Now is the date the age is calculated at, the current time when zero
+	Now time.Time
+}
+
+// AgeClaims derives the age claims for a holder from their birthdate, so a verifier can check an age without learning
+// the birthdate: an age_equal_or_over object with a boolean for each threshold, age_in_years and age_birth_year. It
+// returns the claims to add to those passed to Issue together with their selectively disclosable paths, each threshold
+// being a separate disclosure. A holder born on 29 February reaches a new age on 1 March in non-leap years.
+func AgeClaims(birthdate time.Time, opts AgeOptions) (map[string]any, []string, error) {
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	thresholds := opts.Thresholds
+	if len(thresholds) == 0 {
+		thresholds = DefaultAgeThresholds()
+	}
+
+	age := ageAt(birthdate, now)
+	if age < 0 {
+		return nil, nil, fmt.Errorf("birthdate %s is after %s", birthdate.Format(time.DateOnly), now.Format(time.DateOnly))
+	}
+
+	over := make(map[string]any, len(thresholds))
+	paths := make([]string, 0, len(thresholds)+2)
+	for _, threshold := range thresholds {
+		if threshold < 0 {
+			return nil, nil, fmt.Errorf("age threshold %d must not be negative", threshold)
+		}
+		name := strconv.Itoa(threshold)
+		if _, ok := over[name]; ok {
+			return nil, nil, fmt.Errorf("age threshold %d is listed more than once", threshold)
+		}
+		over[name] = age >= threshold
+		paths = append(paths, go_sd_jwt.ClaimPath{"age_equal_or_over", name}.String())
+	}
+
+	claims := map[string]any{
+		"age_equal_or_over": over,
+		"age_in_years":      age,
+		"age_birth_year":    birthdate.Year(),
+	}
+	paths = append(paths, "age_in_years", "age_birth_year")
+	return claims, paths, nil
+}
+
+// AgeEqualOrOver reports whether the holder was at least age years old, using only the disclosed claims of an SD-JWT VC,
+// e.g. Credential.Claims. The answer is taken from the first of:
+//   - the age_equal_or_over entry for age
+//   - a true age_equal_or_over entry for an older age, or a false entry for a younger age
+//   - age_in_years, which can only prove the holder is at least age
+//   - age_birth_year, compared against the year of now, the current time when zero
+//
+// The age_equal_or_over entries and age_in_years reflect the holder's age at issuance. An error is returned if the
+// disclosed claims cannot answer the question, or if the age_equal_or_over entries contradict each other, e.g. a false
+// entry for 18 alongside a true entry for 21.
+func AgeEqualOrOver(claims map[string]any, age int, now time.Time) (bool, error) {
+	if v, ok := claims["age_equal_or_over"]; ok {
+		over, ok := v.(map[string]any)
+		if !ok {
+			return false, errors.New("age_equal_or_over claim must be an object")
+		}
+		thresholds := make([]int, 0, len(over))
+		values := make(map[int]bool, len(over))
+		for name, value := range over {
+			threshold, err := strconv.Atoi(name)
+			if err != nil || threshold < 0 || strconv.Itoa(threshold) != name {
+				return false, fmt.Errorf("age_equal_or_over contains an invalid threshold %s", name)
+			}
+			b, ok := value.(bool)
+			if !ok {
+				return false, fmt.Errorf("age_equal_or_over %s claim must be a boolean", name)
+			}
+			thresholds = append(thresholds, threshold)
+			values[threshold] = b
+		}
+		slices.Sort(thresholds)
+
+		// the entries must be true up to the holder's age and false above it
+		for i := 1; i < len(thresholds); i++ {
+			if !values[thresholds[i-1]] && values[thresholds[i]] {
+				return false, fmt.Errorf("age_equal_or_over is inconsistent: %d is false but %d is true", thresholds[i-1], thresholds[i])
+			}
+		}
+
+		for _, threshold := range thresholds {
+			b := values[threshold]
+			switch {
+			case threshold == age:
+				return b, nil
+			case b && threshold > age:
+				return true, nil
+			case !b && threshold < age:
+				return false, nil
+			}
+		}
+	}
+
+	if v, ok := claims["age_in_years"]; ok {
+		years, ok := integer(v)
+		if !ok {
+			return false, errors.New("age_in_years claim must be an integer")
+		}
+		if years >= int64(age) {
+			return true, nil
+		}
+	}
+
+	if v, ok := claims["age_birth_year"]; ok {
+		year, ok := integer(v)
+		if !ok {
+			return false, errors.New("age_birth_year claim must be an integer")
+		}
+		if now.IsZero() {
+			now = time.Now()
+		}
+		// within the year of now the holder is either years or years-1 old
+		years := int64(now.Year()) - year
+		switch {
+		case years-1 >= int64(age):
+			return true, nil
+		case years < int64(age):
+			return false, nil
+		}
+	}
+
+	return false, fmt.Errorf("the disclosed claims do not show whether the holder is at least %d", age)
+}
+
+// ageAt returns the age in whole years at now of someone born on birthdate
+func ageAt(birthdate, now time.Time) int {
+	by, bm, bd := birthdate.Date()
+	ny, nm, nd := now.Date()
+	age := ny - by
+	if nm < bm || (nm == bm && nd < bd) {
+		age--
+	}
+	return age
+}
@@ -0,0 +1,264 @@
+package sdjwtvc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(t *testing.T, s string) time.Time {
+	d, err := time.Parse(time.DateOnly, s)
+	require.NoError(t, err)
+	return d
+}
+
+func TestAgeClaims(t *testing.T) {
+	claims, paths, err := sdjwtvc.AgeClaims(date(t, "2008-06-15"), sdjwtvc.AgeOptions{Now: date(t, "2026-06-14")})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"age_equal_or_over": map[string]any{
+			"12": true,
+			"14": true,
+			"16": true,
+			"18": false,
+			"21": false,
+			"65": false,
+		},
+		"age_in_years":   17,
+		"age_birth_year": 2008,
+	}, claims)
+	assert.Equal(t, []string{
+		"age_equal_or_over.12",
+		"age_equal_or_over.14",
+		"age_equal_or_over.16",
+		"age_equal_or_over.18",
+		"age_equal_or_over.21",
+		"age_equal_or_over.65",
+		"age_in_years",
+		"age_birth_year",
+	}, paths)
+
+	claims, _, err = sdjwtvc.AgeClaims(date(t, "2008-06-15"), sdjwtvc.AgeOptions{Now: date(t, "2026-06-15")})
+	require.NoError(t, err)
+	assert.Equal(t, true, claims["age_equal_or_over"].(map[string]any)["18"])
+	assert.Equal(t, 18, claims["age_in_years"])
+}
+
+func TestAgeClaims_LeapDay(t *testing.T) {
+	opts := sdjwtvc.AgeOptions{Thresholds: []int{18}, Now: date(t, "2026-02-28")}
+	claims, paths, err := sdjwtvc.AgeClaims(date(t, "2008-02-29"), opts)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"18": false}, claims["age_equal_or_over"])
+	assert.Equal(t, []string{"age_equal_or_over.18", "age_in_years", "age_birth_year"}, paths)
+
+	opts.Now = date(t, "2026-03-01")
+	claims, _, err = sdjwtvc.AgeClaims(date(t, "2008-02-29"), opts)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"18": true}, claims["age_equal_or_over"])
+}
+
+func TestAgeClaims_Errors(t *testing.T) {
+	now := date(t, "2026-01-01")
+	tests := []struct {
+		name      string
+		birthdate time.Time
+		opts      sdjwtvc.AgeOptions
+		err       string
+	}{
+		{
+			name:      "birthdate in the future",
+			birthdate: date(t, "2026-01-02"),
+			opts:      sdjwtvc.AgeOptions{Now: now},
+			err:       "birthdate 2026-01-02 is after 2026-01-01",
+		},
+		{
+			name:      "negative threshold",
+			birthdate: date(t, "1990-01-01"),
+			opts:      sdjwtvc.AgeOptions{Now: now, Thresholds: []int{-1}},
+			err:       "age threshold -1 must not be negative",
+		},
+		{
+			name:      "duplicate threshold",
+			birthdate: date(t, "1990-01-01"),
+			opts:      sdjwtvc.AgeOptions{Now: now, Thresholds: []int{18, 21, 18}},
+			err:       "age threshold 18 is listed more than once",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := sdjwtvc.AgeClaims(tt.birthdate, tt.opts)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestAgeEqualOrOver(t *testing.T) {
+	now := date(t, "2026-06-01")
+	tests := []struct {
+		name   string
+		claims map[string]any
+		age    int
+		over   bool
+		err    string
+	}{
+		{
+			name:   "exact threshold",
+			claims: map[string]any{"age_equal_or_over": map[string]any{"18": true}},
+			age:    18,
+			over:   true,
+		},
+		{
+			name:   "exact threshold false",
+			claims: map[string]any{"age_equal_or_over": map[string]any{"18": false}},
+			age:    18,
+		},
+		{
+			name:   "older threshold true",
+			claims: map[string]any{"age_equal_or_over": map[string]any{"21": true}},
+			age:    18,
+			over:   true,
+		},
+		{
+			name:   "younger threshold false",
+			claims: map[string]any{"age_equal_or_over": map[string]any{"16": false}},
+			age:    18,
+		},
+		{
+			name:   "several thresholds",
+			claims: map[string]any{"age_equal_or_over": map[string]any{"12": true, "16": true, "21": false, "65": false}},
+			age:    14,
+			over:   true,
+		},
+		{
+			name:   "inconsistent thresholds",
+			claims: map[string]any{"age_equal_or_over": map[string]any{"16": true, "18": false, "21": true, "65": false}},
+			age:    20,
+			err:    "age_equal_or_over is inconsistent: 18 is false but 21 is true",
+		},
+		{
+			name:   "inconsistent thresholds including age",
+			claims: map[string]any{"age_equal_or_over": map[string]any{"16": false, "18": true}},
+			age:    18,
+			err:    "age_equal_or_over is inconsistent: 16 is false but 18 is true",
+		},
+		{
+			name:   "age in years",
+			claims: map[string]any{"age_equal_or_over": map[string]any{"16": true}, "age_in_years": 19.0},
+			age:    18,
+			over:   true,
+		},
+		{
+			name:   "birth year",
+			claims: map[string]any{"age_in_years": 17.0, "age_birth_year": 2007.0},
+			age:    18,
+			over:   true,
+		},
+		{
+			name:   "birth year too recent",
+			claims: map[string]any{"age_birth_year": 2009.0},
+			age:    18,
+		},
+		{
+			name:   "birth year inconclusive",
+			claims: map[string]any{"age_birth_year": 2008.0},
+			age:    18,
+			err:    "the disclosed claims do not show whether the holder is at least 18",
+		},
+		{
+			name:   "nothing disclosed",
+			claims: map[string]any{"given_name": "Erika"},
+			age:    18,
+			err:    "the disclosed claims do not show whether the holder is at least 18",
+		},
+		{
+			name:   "invalid threshold value",
+			claims: map[string]any{"age_equal_or_over": map[string]any{"18": "yes"}},
+			age:    18,
+			err:    "age_equal_or_over 18 claim must be a boolean",
+		},
+		{
+			name:   "invalid threshold",
+			claims: map[string]any{"age_equal_or_over": map[string]any{"adult": true}},
+			age:    18,
+			err:    "age_equal_or_over contains an invalid threshold adult",
+		},
+		{
+			name:   "non canonical threshold",
+			claims: map[string]any{"age_equal_or_over": map[string]any{"18": false, "018": true}},
+			age:    18,
+			err:    "age_equal_or_over contains an invalid threshold 018",
+		},
+		{
+			name:   "invalid age in years",
+			claims: map[string]any{"age_in_years": "19"},
+			age:    18,
+			err:    "age_in_years claim must be an integer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			over, err := sdjwtvc.AgeEqualOrOver(tt.claims, tt.age, now)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.err, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.over, over)
+		})
+	}
+}
+
+func TestDefaultAgeThresholds(t *testing.T) {
+	thresholds := sdjwtvc.DefaultAgeThresholds()
+	assert.Equal(t, []int{12, 14, 16, 18, 21, 65}, thresholds)
+
+	thresholds[0] = 99
+	assert.Equal(t, []int{12, 14, 16, 18, 21, 65}, sdjwtvc.DefaultAgeThresholds())
+}
+
+func TestAgeClaims_Presentation(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	ageClaims, paths, err := sdjwtvc.AgeClaims(date(t, "1990-03-10"), sdjwtvc.AgeOptions{Now: date(t, "2026-06-01")})
+	require.NoError(t, err)
+	claims := credentialClaims()
+	claims["iss"] = "https://issuer.example.com"
+	claims["birthdate"] = "1990-03-10"
+	for k, v := range ageClaims {
+		claims[k] = v
+	}
+
+	sdJwt, err := sdjwtvc.Issue(claims, sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			SelectivelyDisclosable: append(paths, "birthdate"),
+		},
+		Vct: identityVct,
+	})
+	require.NoError(t, err)
+
+	// the holder presents only the over 18 disclosure
+	sdJwt.Disclosures, err = sdJwt.SelectDisclosures("age_equal_or_over.18")
+	require.NoError(t, err)
+	credential, err := sdjwtvc.Verify(sdJwt, sdjwtvc.VerificationOptions{
+		VerificationOptions: go_sd_jwt.VerificationOptions{IssuerKey: issuerSigner.Public()},
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, credential.Claims, "birthdate")
+	assert.Equal(t, map[string]any{"18": true}, credential.Claims["age_equal_or_over"])
+
+	over, err := sdjwtvc.AgeEqualOrOver(credential.Claims, 18, time.Time{})
+	require.NoError(t, err)
+	assert.True(t, over)
+	_, err = sdjwtvc.AgeEqualOrOver(credential.Claims, 65, time.Time{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,213 @@
+package sdjwtvc
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/disclosure"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/utils"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+)
+
+// ConsentOptions configures the locale of the labels in a Consent
+type ConsentOptions struct {
+	// Locale is the preferred locale of the user as a language tag, e.g. de-DE
+	Locale string
+	// FallbackLocale is used when the metadata has no display entry for Locale, e.g. en-US
+	FallbackLocale string
+}
+
+// Consent describes the disclosures of an SD-JWT VC in the form needed to ask the user which claims to present
+type Consent struct {
+	// Name and Description are the localised display name and description of the credential type
+	Name        string
+	Description string
+	// Items are the disclosures referenced directly from the issuer-signed payload, disclosures nested within the
+	// value of another disclosure are returned as Children of that item
+	Items []*ConsentItem
+}
+
+// ConsentItem is a single disclosure with its localised label
+type ConsentItem struct {
+	Path       go_sd_jwt.ClaimPath
+	Disclosure disclosure.Disclosure
+	// Value is the disclosed value without the digests of any nested disclosures
+	Value any
+	// Label and Description are taken from the display entries of the claim metadata matching Path or, for an array
+	// element without metadata of its own, the metadata of the array. Without a display entry, Label is the claim name
+	Label       string
+	Description string
+	// Locale is the locale of the display entry used for Label, empty when no display entry was found
+	Locale   string
+	Parent   *ConsentItem
+	Children []*ConsentItem
+}
+
+// NewConsent builds the consent model for the disclosures of an SD-JWT VC from its type metadata.
+// Labels are chosen from the display entries of the claim metadata matching each disclosure by the first of:
+// opts.Locale, an entry for the same language as opts.Locale, opts.FallbackLocale and the first entry. Items are
+// ordered by the order of the claim metadata, followed by any disclosures the metadata does not describe.
+// Array indexes in item paths refer to positions within the issuer-signed payload, as for SdJwt.DisclosureTree.
+func NewConsent(sdJwt *go_sd_jwt.SdJwt, metadata *typemetadata.TypeMetadata, opts ConsentOptions) (*Consent, error) {
+	if metadata == nil {
+		return nil, errors.New("type metadata must be provided")
+	}
+	tree, err := sdJwt.DisclosureTree()
+	if err != nil {
+		return nil, err
+	}
+
+	consent := &Consent{}
+	locales := make([]string, len(metadata.Display))
+	for i, d := range metadata.Display {
+		locales[i] = d.Locale
+	}
+	if i := pickLocale(locales, opts.Locale, opts.FallbackLocale); i != -1 {
+		consent.Name = metadata.Display[i].Name
+		consent.Description = metadata.Display[i].Description
+	} else {
+		consent.Name = metadata.Name
+		consent.Description = metadata.Description
+	}
+
+	consent.Items = consentItems(tree, nil, metadata, opts)
+	return consent, nil
+}
+
+// Disclosures returns the disclosures needed to present the provided items, including the disclosures of their parent
+// items, ordered parent first
+func (c *Consent) Disclosures(items ...*ConsentItem) []disclosure.Disclosure {
+	selected := map[*ConsentItem]bool{}
+	for _, item := range items {
+		for ; item != nil; item = item.Parent {
+			selected[item] = true
+		}
+	}
+
+	var result []disclosure.Disclosure
+	var walk func(items []*ConsentItem)
+	walk = func(items []*ConsentItem) {
+		for _, item := range items {
+			if selected[item] {
+				result = append(result, item.Disclosure)
+				walk(item.Children)
+			}
+		}
+	}
+	walk(c.Items)
+	return result
+}
+
+func consentItems(nodes []*go_sd_jwt.DisclosureNode, parent *ConsentItem, metadata *typemetadata.TypeMetadata, opts ConsentOptions) []*ConsentItem {
+	items := make([]*ConsentItem, len(nodes))
+	order := make(map[*ConsentItem]int, len(nodes))
+	for i, n := range nodes {
+		item := &ConsentItem{
+			Path:       n.Path,
+			Disclosure: n.Disclosure,
+			Value:      strippedValue(n.Disclosure.Value),
+			Parent:     parent,
+		}
+		order[item] = len(metadata.Claims)
+		index, claim := claimMetadata(metadata, n.Path)
+		if claim == nil && n.Disclosure.Key == nil {
+			// array elements without metadata of their own are described by the metadata of the array
+			index, claim = claimMetadata(metadata, n.Path[:len(n.Path)-1])
+		}
+		if claim != nil {
+			order[item] = index
+			item.Label, item.Description, item.Locale = claimLabel(claim, opts)
+		}
+		if item.Label == "" {
+			item.Label = defaultLabel(n)
+		}
+		item.Children = consentItems(n.Children, item, metadata, opts)
+		items[i] = item
+	}
+	slices.SortStableFunc(items, func(a, b *ConsentItem) int {
+		return order[a] - order[b]
+	})
+	return items
+}
+
+// claimMetadata returns the claim metadata matching the path and its index
+func claimMetadata(metadata *typemetadata.TypeMetadata, path go_sd_jwt.ClaimPath) (int, *typemetadata.ClaimMetadata) {
+	for i := range metadata.Claims {
+		if metadata.Claims[i].Path.Matches(path) {
+			return i, &metadata.Claims[i]
+		}
+	}
+	return -1, nil
+}
+
+func claimLabel(claim *typemetadata.ClaimMetadata, opts ConsentOptions) (label, description, locale string) {
+	locales := make([]string, len(claim.Display))
+	for i, d := range claim.Display {
+		locales[i] = d.Locale
+	}
+	i := pickLocale(locales, opts.Locale, opts.FallbackLocale)
+	if i == -1 {
+		return "", "", ""
+	}
+	return claim.Display[i].Label, claim.Display[i].Description, claim.Display[i].Locale
+}
+
+// defaultLabel returns the label for a disclosure the metadata has no display entry for, the name of the claim or, for
+// an array element, the name of the array
+func defaultLabel(n *go_sd_jwt.DisclosureNode) string {
+	if n.Disclosure.Key != nil {
+		return *n.Disclosure.Key
+	}
+	for i := len(n.Path) - 1; i >= 0; i-- {
+		if name, ok := n.Path[i].(string); ok {
+			return name
+		}
+	}
+	return n.Path.String()
+}
+
+// pickLocale returns the index of the locale to use, or -1 if there are none
+func pickLocale(locales []string, locale, fallbackLocale string) int {
+	if len(locales) == 0 {
+		return -1
+	}
+	if locale != "" {
+		for i, l := range locales {
+			if strings.EqualFold(l, locale) {
+				return i
+			}
+		}
+		language := baseLanguage(locale)
+		for i, l := range locales {
+			if strings.EqualFold(baseLanguage(l), language) {
+				return i
+			}
+		}
+	}
+	if fallbackLocale != "" {
+		for i, l := range locales {
+			if strings.EqualFold(l, fallbackLocale) {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+func baseLanguage(tag string) string {
+	language, _, _ := strings.Cut(tag, "-")
+	return language
+}
+
+func strippedValue(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		return utils.StripSDClaims(value)
+	case []any:
+		return utils.StripSDClaimsFromSlice(value)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,142 @@
+package sdjwtvc_test
+
+import (
+	"testing"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func consentMetadata() *typemetadata.TypeMetadata {
+	return &typemetadata.TypeMetadata{
+		Vct:  identityVct,
+		Name: "Identity Credential",
+		Display: []typemetadata.Display{
+			{Locale: "en-US", Name: "Identity Credential", Description: "Your identity"},
+			{Locale: "de-DE", Name: "Identitätsnachweis", Description: "Ihre Identität"},
+		},
+		Claims: []typemetadata.ClaimMetadata{
+			{Path: go_sd_jwt.ClaimPath{"family_name"}, Display: []typemetadata.ClaimDisplay{
+				{Locale: "en-US", Label: "Family Name"},
+				{Locale: "de-DE", Label: "Nachname", Description: "Der Familienname"},
+			}},
+			{Path: go_sd_jwt.ClaimPath{"given_name"}, Display: []typemetadata.ClaimDisplay{
+				{Locale: "en-US", Label: "Given Name"},
+				{Locale: "de-DE", Label: "Vorname"},
+			}},
+			{Path: go_sd_jwt.ClaimPath{"address"}, Display: []typemetadata.ClaimDisplay{
+				{Locale: "en-US", Label: "Address"},
+				{Locale: "de", Label: "Adresse"},
+			}},
+			{Path: go_sd_jwt.ClaimPath{"address", "street_address"}, Display: []typemetadata.ClaimDisplay{
+				{Locale: "en-US", Label: "Street"},
+			}},
+			{Path: go_sd_jwt.ClaimPath{"nationalities"}, Display: []typemetadata.ClaimDisplay{
+				{Locale: "en-US", Label: "Nationality"},
+				{Locale: "de-DE", Label: "Staatsangehörigkeit"},
+			}},
+		},
+	}
+}
+
+func consentSdJwt(t *testing.T) *go_sd_jwt.SdJwt {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := credentialClaims()
+	claims["nationalities"] = []string{"DE"}
+	claims["birthdate"] = "1963-08-12"
+	sdJwt, err := sdjwtvc.Issue(claims, sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer: issuerSigner,
+			SelectivelyDisclosable: []string{
+				"given_name", "family_name", "address", "address.street_address", "nationalities[*]", "birthdate",
+			},
+		},
+		Vct: identityVct,
+	})
+	require.NoError(t, err)
+	return sdJwt
+}
+
+func TestNewConsent(t *testing.T) {
+	sdJwt := consentSdJwt(t)
+
+	consent, err := sdjwtvc.NewConsent(sdJwt, consentMetadata(), sdjwtvc.ConsentOptions{Locale: "de-AT", FallbackLocale: "en-US"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Identitätsnachweis", consent.Name)
+	assert.Equal(t, "Ihre Identität", consent.Description)
+
+	require.Len(t, consent.Items, 5)
+	labels := make([]string, len(consent.Items))
+	for i, item := range consent.Items {
+		labels[i] = item.Label
+	}
+	assert.Equal(t, []string{"Nachname", "Vorname", "Adresse", "Staatsangehörigkeit", "birthdate"}, labels)
+
+	familyName := consent.Items[0]
+	assert.Equal(t, go_sd_jwt.ClaimPath{"family_name"}, familyName.Path)
+	assert.Equal(t, "Der Familienname", familyName.Description)
+	assert.Equal(t, "de-DE", familyName.Locale)
+	assert.Equal(t, "Mustermann", familyName.Value)
+
+	address := consent.Items[2]
+	assert.Equal(t, "de", address.Locale)
+	assert.Equal(t, map[string]any{"locality": "Köln"}, address.Value)
+	require.Len(t, address.Children, 1)
+	street := address.Children[0]
+	assert.Equal(t, go_sd_jwt.ClaimPath{"address", "street_address"}, street.Path)
+	assert.Equal(t, "Street", street.Label, "the fallback locale should be used")
+	assert.Equal(t, "en-US", street.Locale)
+	assert.Same(t, address, street.Parent)
+
+	birthdate := consent.Items[4]
+	assert.Empty(t, birthdate.Locale)
+}
+
+func TestNewConsent_ArrayElements(t *testing.T) {
+	sdJwt := consentSdJwt(t)
+
+	consent, err := sdjwtvc.NewConsent(sdJwt, consentMetadata(), sdjwtvc.ConsentOptions{Locale: "en-US"})
+	require.NoError(t, err)
+
+	// the nationalities array is plaintext, so its element is a root item labelled by the array metadata
+	nationality := consent.Items[3]
+	assert.Equal(t, go_sd_jwt.ClaimPath{"nationalities", 0}, nationality.Path)
+	assert.Equal(t, "Nationality", nationality.Label)
+	assert.Equal(t, "DE", nationality.Value)
+}
+
+func TestConsent_Disclosures(t *testing.T) {
+	sdJwt := consentSdJwt(t)
+
+	consent, err := sdjwtvc.NewConsent(sdJwt, consentMetadata(), sdjwtvc.ConsentOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Identity Credential", consent.Name)
+
+	street := consent.Items[2].Children[0]
+
+	disclosures := consent.Disclosures(consent.Items[1], street)
+	require.Len(t, disclosures, 3)
+	assert.Equal(t, "given_name", *disclosures[0].Key)
+	assert.Equal(t, "address", *disclosures[1].Key)
+	assert.Equal(t, "street_address", *disclosures[2].Key)
+
+	sdJwt.Disclosures = disclosures
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	require.NoError(t, err)
+	assert.Equal(t, "Heidestraße 17", disclosed["address"].(map[string]any)["street_address"])
+	assert.NotContains(t, disclosed, "family_name")
+}
+
+func TestNewConsent_Errors(t *testing.T) {
+	_, err := sdjwtvc.NewConsent(consentSdJwt(t), nil, sdjwtvc.ConsentOptions{})
+	require.Error(t, err)
+	assert.Equal(t, "type metadata must be provided", err.Error())
+}
@@ -0,0 +1,125 @@
+package sdjwtvc
+
+import (
+	"fmt"
+	"slices"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+)
+
+// IssuanceOptions configures how Issue builds and signs an SD-JWT VC.
+// The embedded IssuanceOptions are passed to go_sd_jwt.Issue, Typ defaults to dc+sd-jwt.
+type IssuanceOptions struct {
+	go_sd_jwt.IssuanceOptions
+	// Vct is the verifiable credential type, added as the vct claim. It is required unless the claims contain vct
+	Vct string
+	// VctIntegrity is the integrity metadata of the type metadata document for Vct, added as the vct#integrity claim
+	VctIntegrity string
+	// Iss is the issuer identifier, added as the iss claim
+	Iss string
+	// Status is added as the status claim
+	Status *Status
+	// TypeMetadata is the resolved type metadata for the vct. When set, claims it requires to always be selectively
+	// disclosable are added to SelectivelyDisclosable, claims it requires to never be selectively disclosable must
+	// not be selected and, when it includes a schema, the claims must match the schema
+	TypeMetadata *typemetadata.TypeMetadata
+}
+
+// Issue creates a new signed SD-JWT VC from the provided claims.
+// The vct, vct#integrity, iss and status claims may either be provided in the claims or through their option, not
+// both. An error is returned if any of the claims listed in NonSelectivelyDisclosableClaims, or any claim beneath
+// them, is matched by a path in opts.SelectivelyDisclosable, or if a registered claim has an invalid type.
+// The provided claims map is not modified.
+func Issue(claims map[string]any, opts IssuanceOptions) (*go_sd_jwt.SdJwt, error) {
+	vcClaims := make(map[string]any, len(claims)+4)
+	for k, v := range claims {
+		vcClaims[k] = v
+	}
+
+	if opts.Vct != "" {
+		if err := addRegisteredClaim(vcClaims, "vct", opts.Vct); err != nil {
+			return nil, err
+		}
+	}
+	if opts.VctIntegrity != "" {
+		if err := addRegisteredClaim(vcClaims, "vct#integrity", opts.VctIntegrity); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Iss != "" {
+		if err := addRegisteredClaim(vcClaims, "iss", opts.Iss); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Status != nil {
+		if err := addRegisteredClaim(vcClaims, "status", opts.Status.claim()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateRegisteredClaims(vcClaims); err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+	}
+
+	issuance := opts.IssuanceOptions
+	if opts.TypeMetadata != nil {
+		if opts.TypeMetadata.Vct != vcClaims["vct"] {
+			return nil, fmt.Errorf("%wtype metadata is for vct %s", e.ErrInvalidIssuance, opts.TypeMetadata.Vct)
+		}
+		paths, err := applySdRules(vcClaims, opts.SelectivelyDisclosable, opts.TypeMetadata)
+		if err != nil {
+			return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+		}
+		issuance.SelectivelyDisclosable = paths
+
+		if schema := opts.TypeMetadata.Schema; schema != nil {
+			// the schema applies to the final claims, including the cnf, iat, nbf and exp claims added while issuing
+			validate := opts.ValidateClaims
+			issuance.ValidateClaims = func(claims map[string]any) error {
+				if validate != nil {
+					if err := validate(claims); err != nil {
+						return err
+					}
+				}
+				return ValidateClaims(claims, schema)
+			}
+		}
+	}
+
+	for _, path := range issuance.SelectivelyDisclosable {
+		claimPath, err := go_sd_jwt.ParseClaimPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+		}
+		if len(claimPath) == 0 {
+			continue
+		}
+		if name, ok := claimPath[0].(string); ok && slices.Contains(NonSelectivelyDisclosableClaims, name) {
+			return nil, fmt.Errorf("%wclaim %s must not be selectively disclosable in an SD-JWT VC", e.ErrInvalidIssuance, name)
+		}
+	}
+
+	if issuance.Typ == "" {
+		if typ, ok := issuance.Header["typ"]; ok {
+			if s, _ := typ.(string); !validTyp(s) {
+				return nil, fmt.Errorf("%wtyp header must be %s, got %v", e.ErrInvalidIssuance, Typ, typ)
+			}
+		} else {
+			issuance.Typ = Typ
+		}
+	} else if !validTyp(issuance.Typ) {
+		return nil, fmt.Errorf("%wtyp header must be %s, got %s", e.ErrInvalidIssuance, Typ, issuance.Typ)
+	}
+
+	return go_sd_jwt.Issue(vcClaims, issuance)
+}
+
+func addRegisteredClaim(claims map[string]any, name string, value any) error {
+	if _, ok := claims[name]; ok {
+		return fmt.Errorf("%wclaim %s is set in both the claims and the issuance options", e.ErrInvalidIssuance, name)
+	}
+	claims[name] = value
+	return nil
+}
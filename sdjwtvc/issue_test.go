@@ -0,0 +1,211 @@
+package sdjwtvc_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func credentialClaims() map[string]any {
+	return map[string]any{
+		"iat":         1683000000,
+		"exp":         1883000000,
+		"given_name":  "Erika",
+		"family_name": "Mustermann",
+		"address": map[string]any{
+			"street_address": "Heidestraße 17",
+			"locality":       "Köln",
+		},
+	}
+}
+
+func TestIssue(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cnf, err := go_sd_jwt.NewJwkConfirmation(&holderKey.PublicKey)
+	require.NoError(t, err)
+
+	sdJwt, err := sdjwtvc.Issue(credentialClaims(), sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			Cnf:                    cnf,
+			SelectivelyDisclosable: []string{"given_name", "family_name", "address.street_address"},
+		},
+		Vct: "https://credentials.example.com/identity_credential",
+		Iss: "https://issuer.example.com",
+		Status: &sdjwtvc.Status{
+			StatusList: &sdjwtvc.StatusListReference{Idx: 412, Uri: "https://issuer.example.com/statuslists/1"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, sdjwtvc.Typ, *sdJwt.Typ())
+	assert.Equal(t, "https://credentials.example.com/identity_credential", sdJwt.Body["vct"])
+	assert.Equal(t, "https://issuer.example.com", sdJwt.Body["iss"])
+	assert.Equal(t, map[string]any{
+		"status_list": map[string]any{"idx": float64(412), "uri": "https://issuer.example.com/statuslists/1"},
+	}, sdJwt.Body["status"])
+	assert.Contains(t, sdJwt.Body, "cnf")
+	assert.Contains(t, sdJwt.Body, "exp")
+	assert.NotContains(t, sdJwt.Body, "given_name")
+	assert.Len(t, sdJwt.Disclosures, 3)
+
+	require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: issuerSigner.Public()}))
+}
+
+func TestIssue_VctInClaims(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := credentialClaims()
+	claims["vct"] = "https://credentials.example.com/identity_credential"
+	sdJwt, err := sdjwtvc.Issue(claims, sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer: issuerSigner,
+			Typ:    sdjwtvc.LegacyTyp,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, sdjwtvc.LegacyTyp, *sdJwt.Typ())
+	assert.Equal(t, "https://credentials.example.com/identity_credential", sdJwt.Body["vct"])
+	assert.NotContains(t, claims, "iss", "the provided claims should not be modified")
+}
+
+func TestIssue_Errors(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	vct := "https://credentials.example.com/identity_credential"
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+		opts   sdjwtvc.IssuanceOptions
+		err    string
+	}{
+		{
+			name:   "missing vct",
+			claims: credentialClaims(),
+			opts:   sdjwtvc.IssuanceOptions{},
+			err:    "invalid issuance: vct claim is required",
+		},
+		{
+			name: "vct not a string",
+			claims: func() map[string]any {
+				claims := credentialClaims()
+				claims["vct"] = 1
+				return claims
+			}(),
+			err: "invalid issuance: vct claim must be a non-empty string",
+		},
+		{
+			name: "vct in claims and options",
+			claims: func() map[string]any {
+				claims := credentialClaims()
+				claims["vct"] = vct
+				return claims
+			}(),
+			opts: sdjwtvc.IssuanceOptions{Vct: vct},
+			err:  "invalid issuance: claim vct is set in both the claims and the issuance options",
+		},
+		{
+			name: "exp not a number",
+			claims: func() map[string]any {
+				claims := credentialClaims()
+				claims["exp"] = "tomorrow"
+				return claims
+			}(),
+			opts: sdjwtvc.IssuanceOptions{Vct: vct},
+			err:  "invalid issuance: exp claim must be a numeric date",
+		},
+		{
+			name: "cnf not an object",
+			claims: func() map[string]any {
+				claims := credentialClaims()
+				claims["cnf"] = "holder"
+				return claims
+			}(),
+			opts: sdjwtvc.IssuanceOptions{Vct: vct},
+			err:  "invalid issuance: cnf claim must be an object",
+		},
+		{
+			name:   "invalid status list reference",
+			claims: credentialClaims(),
+			opts: sdjwtvc.IssuanceOptions{
+				Vct:    vct,
+				Status: &sdjwtvc.Status{StatusList: &sdjwtvc.StatusListReference{Idx: -1, Uri: "https://issuer.example.com/statuslists/1"}},
+			},
+			err: "invalid issuance: status_list idx must be a non-negative integer",
+		},
+		{
+			name:   "empty status",
+			claims: credentialClaims(),
+			opts:   sdjwtvc.IssuanceOptions{Vct: vct, Status: &sdjwtvc.Status{}},
+			err:    "invalid issuance: status claim must contain at least one status mechanism",
+		},
+		{
+			name:   "other typ",
+			claims: credentialClaims(),
+			opts: sdjwtvc.IssuanceOptions{
+				IssuanceOptions: go_sd_jwt.IssuanceOptions{Typ: "example+sd-jwt"},
+				Vct:             vct,
+			},
+			err: "invalid issuance: typ header must be dc+sd-jwt, got example+sd-jwt",
+		},
+		{
+			name:   "other typ in header",
+			claims: credentialClaims(),
+			opts: sdjwtvc.IssuanceOptions{
+				IssuanceOptions: go_sd_jwt.IssuanceOptions{Header: map[string]any{"typ": "JWT"}},
+				Vct:             vct,
+			},
+			err: "invalid issuance: typ header must be dc+sd-jwt, got JWT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Signer = issuerSigner
+			_, err := sdjwtvc.Issue(tt.claims, tt.opts)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestIssue_NonSelectivelyDisclosable(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := credentialClaims()
+	claims["iss"] = "https://issuer.example.com"
+	claims["nbf"] = 1683000000
+	claims["vct"] = "https://credentials.example.com/identity_credential"
+	claims["vct#integrity"] = "sha256-WRL5ca/xGgEabcdefghijklmnopqrstuvwxyz01234567"
+	claims["status"] = map[string]any{"status_list": map[string]any{"idx": 1, "uri": "https://issuer.example.com/statuslists/1"}}
+
+	for _, path := range []string{"iss", "nbf", "exp", "cnf", "cnf.kid", "vct", "vct#integrity", "status", "status.status_list.idx"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := sdjwtvc.Issue(claims, sdjwtvc.IssuanceOptions{
+				IssuanceOptions: go_sd_jwt.IssuanceOptions{
+					Signer:                 issuerSigner,
+					Cnf:                    &go_sd_jwt.Confirmation{Kid: "holder-key-1"},
+					SelectivelyDisclosable: []string{"given_name", path},
+				},
+			})
+			require.Error(t, err)
+			name, _, _ := strings.Cut(path, ".")
+			assert.Equal(t, "invalid issuance: claim "+name+" must not be selectively disclosable in an SD-JWT VC", err.Error())
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package sdjwtvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/utils"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+)
+
+const (
+	// PidVct is the vct of the EU Person Identification Data (PID) credential
+	PidVct = "urn:eudi:pid:1"
+	// LegacyPidVct is the vct used by earlier versions of the PID rulebook, accepted for compatibility
+	LegacyPidVct = "urn:eu.europa.ec.eudi:pid:1"
+)
+
+// PidMandatoryClaims lists the attributes every PID must contain according to the PID rulebook
+var PidMandatoryClaims = []string{
+	"family_name",
+	"given_name",
+	"birthdate",
+	"place_of_birth",
+	"nationalities",
+	"date_of_expiry",
+	"issuing_authority",
+	"issuing_country",
+}
+
+// Pid holds the attributes of a PID in their SD-JWT VC encoding. The sdjwt struct tags apply the disclosure layout of
+// the PID rulebook: every attribute is selectively disclosable, as is each member of place_of_birth and address and each
+// element of nationalities.
+type Pid struct {
+	FamilyName                   string           `json:"family_name,omitempty" sdjwt:"sd"`
+	GivenName                    string           `json:"given_name,omitempty" sdjwt:"sd"`
+	Birthdate                    PidDate          `json:"birthdate,omitzero" sdjwt:"sd"`
+	PlaceOfBirth                 *PidPlaceOfBirth `json:"place_of_birth,omitempty" sdjwt:"sd"`
+	Nationalities                []string         `json:"nationalities,omitempty" sdjwt:"sd,elements"`
+	Address                      *PidAddress      `json:"address,omitempty" sdjwt:"sd"`
+	PersonalAdministrativeNumber string           `json:"personal_administrative_number,omitempty" sdjwt:"sd"`
+	Picture                      string           `json:"picture,omitempty" sdjwt:"sd"`
+	BirthFamilyName              string           `json:"birth_family_name,omitempty" sdjwt:"sd"`
+	BirthGivenName               string           `json:"birth_given_name,omitempty" sdjwt:"sd"`
+	// Sex is one of the ISO/IEC 5218 codes 0, 1, 2 or 9, or 5 (inapplicable) or 6 (other)
+	Sex                 *int    `json:"sex,omitempty" sdjwt:"sd"`
+	Email               string  `json:"email,omitempty" sdjwt:"sd"`
+	PhoneNumber         string  `json:"phone_number,omitempty" sdjwt:"sd"`
+	DateOfExpiry        PidDate `json:"date_of_expiry,omitzero" sdjwt:"sd"`
+	DateOfIssuance      PidDate `json:"date_of_issuance,omitzero" sdjwt:"sd"`
+	IssuingAuthority    string  `json:"issuing_authority,omitempty" sdjwt:"sd"`
+	IssuingCountry      string  `json:"issuing_country,omitempty" sdjwt:"sd"`
+	IssuingJurisdiction string  `json:"issuing_jurisdiction,omitempty" sdjwt:"sd"`
+	DocumentNumber      string  `json:"document_number,omitempty" sdjwt:"sd"`
+	TrustAnchor         string  `json:"trust_anchor,omitempty" sdjwt:"sd"`
+}
+
+// PidPlaceOfBirth is the place_of_birth attribute of a PID, at least one member must be provided
+type PidPlaceOfBirth struct {
+	Country  string `json:"country,omitempty" sdjwt:"sd"`
+	Region   string `json:"region,omitempty" sdjwt:"sd"`
+	Locality string `json:"locality,omitempty" sdjwt:"sd"`
+}
+
+// PidAddress is the address attribute of a PID
+type PidAddress struct {
+	Formatted     string `json:"formatted,omitempty" sdjwt:"sd"`
+	StreetAddress string `json:"street_address,omitempty" sdjwt:"sd"`
+	HouseNumber   string `json:"house_number,omitempty" sdjwt:"sd"`
+	PostalCode    string `json:"postal_code,omitempty" sdjwt:"sd"`
+	Locality      string `json:"locality,omitempty" sdjwt:"sd"`
+	Region        string `json:"region,omitempty" sdjwt:"sd"`
+	Country       string `json:"country,omitempty" sdjwt:"sd"`
+}
+
+// PidDate is a calendar date encoded as an ISO 8601 full-date, e.g. 1964-08-12
+type PidDate struct {
+	time.Time
+}
+
+// NewPidDate returns the PidDate for the provided day
+func NewPidDate(year int, month time.Month, day int) PidDate {
+	return PidDate{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func (d PidDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(time.DateOnly))
+}
+
+func (d *PidDate) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("date must be a string: %w", err)
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return fmt.Errorf("date %s must be a full-date: %w", s, err)
+	}
+	d.Time = t
+	return nil
+}
+
+// PidIssuanceOptions configures how IssuePid builds and signs a PID.
+// Vct defaults to PidVct and TypeMetadata to PidTypeMetadata.
+type PidIssuanceOptions struct {
+	IssuanceOptions
+	// Age, when set, adds the age claims derived from the birthdate, see AgeClaims
+	Age *AgeOptions
+}
+
+// IssuePid creates a new signed PID in the disclosure layout of the PID rulebook.
+// The attributes are validated against the schema of the type metadata before signing, so a PID missing a mandatory
+// attribute or with an attribute in the wrong format is rejected. The exp claim is set to the end of date_of_expiry in
+// UTC and the iat claim to opts.Validity.Now().
+func IssuePid(pid *Pid, opts PidIssuanceOptions) (*go_sd_jwt.SdJwt, error) {
+	paths, err := go_sd_jwt.StructDisclosurePaths(pid)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(pid)
+	if err != nil {
+		return nil, fmt.Errorf("%wfailed to marshal pid: %s", e.ErrInvalidIssuance, err.Error())
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return nil, fmt.Errorf("%wfailed to parse pid: %s", e.ErrInvalidIssuance, err.Error())
+	}
+
+	if opts.Age != nil {
+		if pid.Birthdate.IsZero() {
+			return nil, fmt.Errorf("%wage claims require a birthdate", e.ErrInvalidIssuance)
+		}
+		ageClaims, agePaths, err := AgeClaims(pid.Birthdate.Time, *opts.Age)
+		if err != nil {
+			return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+		}
+		for k, v := range ageClaims {
+			claims[k] = v
+		}
+		paths = append(paths, agePaths...)
+	}
+
+	if !pid.DateOfExpiry.IsZero() {
+		claims["exp"] = pid.DateOfExpiry.AddDate(0, 0, 1).Unix()
+	}
+	claims["iat"] = opts.Validity.Now().Unix()
+
+	issuance := opts.IssuanceOptions
+	if issuance.Vct == "" {
+		issuance.Vct = PidVct
+	}
+	if issuance.TypeMetadata == nil {
+		issuance.TypeMetadata = PidTypeMetadata(issuance.Vct)
+	}
+	issuance.SelectivelyDisclosable = append(append([]string{}, issuance.SelectivelyDisclosable...), paths...)
+	return Issue(claims, issuance)
+}
+
+// PidVerificationOptions configures how VerifyPid checks a PID.
+// ExpectedVct defaults to accepting PidVct or LegacyPidVct and TypeMetadata to PidTypeMetadata.
+type PidVerificationOptions struct {
+	VerificationOptions
+	// RequireMandatory requires every attribute in PidMandatoryClaims to be disclosed, e.g. when a wallet receives a
+	// PID from its issuer. A verifier should only set it when it requested all mandatory attributes.
+	RequireMandatory bool
+}
+
+// PidCredential is a verified PID
+type PidCredential struct {
+	*Credential
+	// Pid holds the disclosed PID attributes, attributes which were not disclosed are left empty
+	Pid Pid
+}
+
+// VerifyPid verifies a PID with Verify and checks it against the PID rulebook: the vct, iss and exp claims must be
+// included in plaintext, the attributes must be selectively disclosable, and the disclosed attributes must be in the
+// formats of the rulebook, such as full-dates for birthdate and date_of_expiry and ISO 3166-1 alpha-2 country codes
+// for nationalities and issuing_country.
+func VerifyPid(sdJwt *go_sd_jwt.SdJwt, opts PidVerificationOptions) (*PidCredential, error) {
+	vct, _ := sdJwt.Body["vct"].(string)
+	if opts.ExpectedVct == "" && vct != PidVct && vct != LegacyPidVct {
+		return nil, fmt.Errorf("%wvct must be %s or %s", e.ErrInvalidToken, PidVct, LegacyPidVct)
+	}
+
+	verification := opts.VerificationOptions
+	if verification.TypeMetadata == nil {
+		verification.TypeMetadata = PidTypeMetadata(vct)
+	}
+	verification.ValidateSchema = true
+	credential, err := Verify(sdJwt, verification)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := sdJwt.Body["exp"]; !ok {
+		return nil, fmt.Errorf("%wexp claim must be included in plaintext", e.ErrInvalidToken)
+	}
+	if opts.RequireMandatory {
+		for _, name := range PidMandatoryClaims {
+			if _, ok := credential.Claims[name]; !ok {
+				return nil, fmt.Errorf("%wmandatory pid claim %s is not disclosed", e.ErrInvalidToken, name)
+			}
+		}
+	}
+
+	b, err := json.Marshal(credential.Claims)
+	if err != nil {
+		return nil, fmt.Errorf("%wfailed to marshal claims: %s", e.ErrInvalidToken, err.Error())
+	}
+	result := &PidCredential{Credential: credential}
+	if err := json.Unmarshal(b, &result.Pid); err != nil {
+		return nil, fmt.Errorf("%winvalid pid: %s", e.ErrInvalidToken, err.Error())
+	}
+	return result, nil
+}
+
+// PidTypeMetadata returns type metadata for the provided PID vct describing the disclosure layout of the PID rulebook
+// and including a schema for the formats of its attributes
+func PidTypeMetadata(vct string) *typemetadata.TypeMetadata {
+	metadata := &typemetadata.TypeMetadata{
+		Vct:         vct,
+		Name:        "Person Identification Data",
+		Description: "EU Person Identification Data (PID) following the PID rulebook",
+	}
+
+	// the schema is copied so callers may modify the returned metadata
+	metadata.Schema = utils.CopyMap(pidSchemaObject)
+
+	for _, claim := range pidClaims {
+		metadata.Claims = append(metadata.Claims, typemetadata.ClaimMetadata{
+			Path:    claim.path,
+			Display: []typemetadata.ClaimDisplay{{Locale: "en-US", Label: claim.label}},
+			Sd:      typemetadata.SdAlways,
+		})
+	}
+	return metadata
+}
+
+var pidClaims = []struct {
+	path  go_sd_jwt.ClaimPath
+	label string
+}{
+	{go_sd_jwt.ClaimPath{"family_name"}, "Family name"},
+	{go_sd_jwt.ClaimPath{"given_name"}, "Given name"},
+	{go_sd_jwt.ClaimPath{"birthdate"}, "Date of birth"},
+	{go_sd_jwt.ClaimPath{"place_of_birth"}, "Place of birth"},
+	{go_sd_jwt.ClaimPath{"place_of_birth", "country"}, "Country of birth"},
+	{go_sd_jwt.ClaimPath{"place_of_birth", "region"}, "Region of birth"},
+	{go_sd_jwt.ClaimPath{"place_of_birth", "locality"}, "Locality of birth"},
+	{go_sd_jwt.ClaimPath{"nationalities"}, "Nationalities"},
+	{go_sd_jwt.ClaimPath{"nationalities", nil}, "Nationality"},
+	{go_sd_jwt.ClaimPath{"address"}, "Address"},
+	{go_sd_jwt.ClaimPath{"address", "formatted"}, "Address"},
+	{go_sd_jwt.ClaimPath{"address", "street_address"}, "Street"},
+	{go_sd_jwt.ClaimPath{"address", "house_number"}, "House number"},
+	{go_sd_jwt.ClaimPath{"address", "postal_code"}, "Postal code"},
+	{go_sd_jwt.ClaimPath{"address", "locality"}, "Locality"},
+	{go_sd_jwt.ClaimPath{"address", "region"}, "Region"},
+	{go_sd_jwt.ClaimPath{"address", "country"}, "Country"},
+	{go_sd_jwt.ClaimPath{"personal_administrative_number"}, "Personal administrative number"},
+	{go_sd_jwt.ClaimPath{"picture"}, "Picture"},
+	{go_sd_jwt.ClaimPath{"birth_family_name"}, "Family name at birth"},
+	{go_sd_jwt.ClaimPath{"birth_given_name"}, "Given name at birth"},
+	{go_sd_jwt.ClaimPath{"sex"}, "Sex"},
+	{go_sd_jwt.ClaimPath{"email"}, "Email address"},
+	{go_sd_jwt.ClaimPath{"phone_number"}, "Mobile phone number"},
+	{go_sd_jwt.ClaimPath{"date_of_expiry"}, "Expiry date"},
+	{go_sd_jwt.ClaimPath{"date_of_issuance"}, "Issuance date"},
+	{go_sd_jwt.ClaimPath{"issuing_authority"}, "Issuing authority"},
+	{go_sd_jwt.ClaimPath{"issuing_country"}, "Issuing country"},
+	{go_sd_jwt.ClaimPath{"issuing_jurisdiction"}, "Issuing jurisdiction"},
+	{go_sd_jwt.ClaimPath{"document_number"}, "Document number"},
+	{go_sd_jwt.ClaimPath{"trust_anchor"}, "Trust anchor"},
+	{go_sd_jwt.ClaimPath{"age_equal_or_over", "12"}, "Age over 12"},
+	{go_sd_jwt.ClaimPath{"age_equal_or_over", "14"}, "Age over 14"},
+	{go_sd_jwt.ClaimPath{"age_equal_or_over", "16"}, "Age over 16"},
+	{go_sd_jwt.ClaimPath{"age_equal_or_over", "18"}, "Age over 18"},
+	{go_sd_jwt.ClaimPath{"age_equal_or_over", "21"}, "Age over 21"},
+	{go_sd_jwt.ClaimPath{"age_equal_or_over", "65"}, "Age over 65"},
+	{go_sd_jwt.ClaimPath{"age_in_years"}, "Age in years"},
+	{go_sd_jwt.ClaimPath{"age_birth_year"}, "Year of birth"},
+}
+
+// pidSchemaObject is pidSchema parsed once at initialisation, which panics if the embedded schema is malformed
+var pidSchemaObject = func() map[string]any {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(pidSchema), &schema); err != nil {
+		panic(fmt.Sprintf("sdjwtvc: invalid pid schema: %s", err))
+	}
+	return schema
+}()
+
+const pidSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"properties": {
+		"family_name": {"type": "string", "minLength": 1},
+		"given_name": {"type": "string", "minLength": 1},
+		"birthdate": {"type": "string", "format": "date"},
+		"place_of_birth": {
+			"type": "object",
+			"properties": {
+				"country": {"$ref": "#/$defs/country"},
+				"region": {"type": "string"},
+				"locality": {"type": "string"}
+			}
+		},
+		"nationalities": {"type": "array", "minItems": 1, "items": {"$ref": "#/$defs/country"}},
+		"address": {
+			"type": "object",
+			"properties": {
+				"formatted": {"type": "string"},
+				"street_address": {"type": "string"},
+				"house_number": {"type": "string"},
+				"postal_code": {"type": "string"},
+				"locality": {"type": "string"},
+				"region": {"type": "string"},
+				"country": {"$ref": "#/$defs/country"}
+			}
+		},
+		"personal_administrative_number": {"type": "string"},
+		"picture": {"type": "string"},
+		"birth_family_name": {"type": "string"},
+		"birth_given_name": {"type": "string"},
+		"sex": {"type": "integer", "enum": [0, 1, 2, 5, 6, 9]},
+		"email": {"type": "string", "format": "email"},
+		"phone_number": {"type": "string", "pattern": "^\\+[0-9]+$"},
+		"date_of_expiry": {"type": "string", "format": "date"},
+		"date_of_issuance": {"type": "string", "format": "date"},
+		"issuing_authority": {"type": "string", "minLength": 1},
+		"issuing_country": {"$ref": "#/$defs/country"},
+		"issuing_jurisdiction": {"type": "string", "pattern": "^[A-Z]{2}-[0-9A-Z]{1,3}$"},
+		"document_number": {"type": "string"},
+		"trust_anchor": {"type": "string", "format": "uri"},
+		"age_equal_or_over": {
+			"type": "object",
+			"patternProperties": {"^[0-9]+$": {"type": "boolean"}},
+			"additionalProperties": false
+		},
+		"age_in_years": {"type": "integer", "minimum": 0},
+		"age_birth_year": {"type": "integer"}
+	},
+	"required": [
+		"family_name",
+		"given_name",
+		"birthdate",
+		"place_of_birth",
+		"nationalities",
+		"date_of_expiry",
+		"issuing_authority",
+		"issuing_country"
+	],
+	"$defs": {
+		"country": {"type": "string", "pattern": "^[A-Z]{2}$"}
+	}
+}`
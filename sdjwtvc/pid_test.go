@@ -0,0 +1,266 @@
+package sdjwtvc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pid() *sdjwtvc.Pid {
+	sex := 2
+	return &sdjwtvc.Pid{
+		FamilyName:       "Mustermann",
+		GivenName:        "Erika",
+		Birthdate:        sdjwtvc.NewPidDate(1964, time.August, 12),
+		PlaceOfBirth:     &sdjwtvc.PidPlaceOfBirth{Country: "DE", Locality: "Berlin"},
+		Nationalities:    []string{"DE", "AT"},
+		Address:          &sdjwtvc.PidAddress{StreetAddress: "Heidestraße", HouseNumber: "17", PostalCode: "51147", Locality: "Köln", Country: "DE"},
+		Sex:              &sex,
+		DateOfExpiry:     sdjwtvc.NewPidDate(2031, time.December, 31),
+		IssuingAuthority: "DE",
+		IssuingCountry:   "DE",
+	}
+}
+
+func TestPidTypeMetadata(t *testing.T) {
+	metadata := sdjwtvc.PidTypeMetadata(sdjwtvc.PidVct)
+	assert.Equal(t, sdjwtvc.PidVct, metadata.Vct)
+	require.NotNil(t, metadata.Schema)
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", metadata.Schema["$schema"])
+
+	// the schema only uses supported keywords
+	assert.NoError(t, sdjwtvc.ValidateClaims(map[string]any{
+		"family_name":       "Mustermann",
+		"given_name":        "Erika",
+		"birthdate":         "1964-08-12",
+		"place_of_birth":    map[string]any{"country": "DE"},
+		"nationalities":     []string{"DE"},
+		"date_of_expiry":    "2031-12-31",
+		"issuing_authority": "DE",
+		"issuing_country":   "DE",
+	}, metadata.Schema))
+
+	// each call returns its own copy of the schema
+	metadata.Schema["properties"].(map[string]any)["family_name"] = false
+	delete(metadata.Schema, "required")
+	other := sdjwtvc.PidTypeMetadata(sdjwtvc.PidVct)
+	assert.NotEqual(t, false, other.Schema["properties"].(map[string]any)["family_name"])
+	assert.Contains(t, other.Schema, "required")
+}
+
+func TestIssuePid(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	sdJwt, err := sdjwtvc.IssuePid(pid(), sdjwtvc.PidIssuanceOptions{
+		IssuanceOptions: sdjwtvc.IssuanceOptions{
+			IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+			Iss:             "https://pid-issuer.example.com",
+		},
+		Age: &sdjwtvc.AgeOptions{Thresholds: []int{18, 65}, Now: time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, sdjwtvc.PidVct, sdJwt.Body["vct"])
+	assert.Equal(t, float64(time.Date(2032, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()), sdJwt.Body["exp"])
+	for _, name := range sdjwtvc.PidMandatoryClaims {
+		assert.NotContains(t, sdJwt.Body, name)
+	}
+
+	tree, err := sdJwt.DisclosureTree()
+	require.NoError(t, err)
+	var paths []string
+	var walk func(nodes []*go_sd_jwt.DisclosureNode)
+	walk = func(nodes []*go_sd_jwt.DisclosureNode) {
+		for _, n := range nodes {
+			paths = append(paths, n.Path.String())
+			walk(n.Children)
+		}
+	}
+	walk(tree)
+	assert.ElementsMatch(t, []string{
+		"family_name", "given_name", "birthdate",
+		"place_of_birth", "place_of_birth.country", "place_of_birth.locality",
+		"nationalities", "nationalities[0]", "nationalities[1]",
+		"address", "address.street_address", "address.house_number", "address.postal_code", "address.locality", "address.country",
+		"sex", "date_of_expiry", "issuing_authority", "issuing_country",
+		"age_equal_or_over.18", "age_equal_or_over.65", "age_in_years", "age_birth_year",
+	}, paths)
+
+	credential, err := sdjwtvc.VerifyPid(sdJwt, sdjwtvc.PidVerificationOptions{
+		VerificationOptions: sdjwtvc.VerificationOptions{
+			VerificationOptions: go_sd_jwt.VerificationOptions{IssuerKey: issuerSigner.Public()},
+		},
+		RequireMandatory: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, *pid(), credential.Pid)
+	assert.Equal(t, "https://pid-issuer.example.com", credential.Iss)
+	assert.Equal(t, map[string]any{"18": true, "65": false}, credential.Claims["age_equal_or_over"])
+}
+
+func TestIssuePid_Errors(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(p *sdjwtvc.Pid)
+		err    string
+	}{
+		{
+			name:   "missing mandatory attribute",
+			modify: func(p *sdjwtvc.Pid) { p.IssuingAuthority = "" },
+			err:    "invalid issuance: claims do not match the schema: claim issuing_authority is required",
+		},
+		{
+			name:   "invalid nationality",
+			modify: func(p *sdjwtvc.Pid) { p.Nationalities = []string{"DEU"} },
+			err:    "invalid issuance: claims do not match the schema: claim nationalities[0] must match the pattern ^[A-Z]{2}$",
+		},
+		{
+			name: "invalid sex",
+			modify: func(p *sdjwtvc.Pid) {
+				sex := 3
+				p.Sex = &sex
+			},
+			err: "invalid issuance: claims do not match the schema: claim sex must be one of the values in enum",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := pid()
+			tt.modify(p)
+			_, err := sdjwtvc.IssuePid(p, sdjwtvc.PidIssuanceOptions{
+				IssuanceOptions: sdjwtvc.IssuanceOptions{
+					IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+					Iss:             "https://pid-issuer.example.com",
+				},
+			})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestVerifyPid_Presentation(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	sdJwt, err := sdjwtvc.IssuePid(pid(), sdjwtvc.PidIssuanceOptions{
+		IssuanceOptions: sdjwtvc.IssuanceOptions{
+			IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+			Iss:             "https://pid-issuer.example.com",
+		},
+	})
+	require.NoError(t, err)
+
+	sdJwt.Disclosures, err = sdJwt.SelectDisclosures("family_name", "nationalities[1]")
+	require.NoError(t, err)
+	opts := sdjwtvc.PidVerificationOptions{
+		VerificationOptions: sdjwtvc.VerificationOptions{
+			VerificationOptions: go_sd_jwt.VerificationOptions{IssuerKey: issuerSigner.Public()},
+		},
+	}
+	credential, err := sdjwtvc.VerifyPid(sdJwt, opts)
+	require.NoError(t, err)
+	assert.Equal(t, sdjwtvc.Pid{FamilyName: "Mustermann", Nationalities: []string{"AT"}}, credential.Pid)
+
+	opts.RequireMandatory = true
+	_, err = sdjwtvc.VerifyPid(sdJwt, opts)
+	require.Error(t, err)
+	assert.Equal(t, "invalid token: mandatory pid claim given_name is not disclosed", err.Error())
+}
+
+func TestVerifyPid_Errors(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := map[string]any{
+		"iss":               "https://pid-issuer.example.com",
+		"exp":               1983000000,
+		"family_name":       "Mustermann",
+		"given_name":        "Erika",
+		"birthdate":         "12.08.1964",
+		"place_of_birth":    map[string]any{"country": "DE"},
+		"nationalities":     []string{"DE"},
+		"date_of_expiry":    "2031-12-31",
+		"issuing_authority": "DE",
+		"issuing_country":   "DE",
+	}
+
+	tests := []struct {
+		name   string
+		vct    string
+		modify func(claims map[string]any)
+		paths  []string
+		err    string
+	}{
+		{
+			name:  "not a pid",
+			vct:   identityVct,
+			paths: []string{"family_name"},
+			err:   "invalid token: vct must be urn:eudi:pid:1 or urn:eu.europa.ec.eudi:pid:1",
+		},
+		{
+			name:  "attribute in plaintext",
+			vct:   sdjwtvc.LegacyPidVct,
+			paths: []string{"given_name", "birthdate", "place_of_birth", "place_of_birth.country", "nationalities", "nationalities[0]", "date_of_expiry", "issuing_authority", "issuing_country"},
+			err:   "invalid token: claim family_name must always be selectively disclosable",
+		},
+		{
+			name: "missing exp",
+			vct:  sdjwtvc.PidVct,
+			modify: func(claims map[string]any) {
+				delete(claims, "exp")
+				claims["birthdate"] = "1964-08-12"
+			},
+			paths: []string{"family_name", "given_name", "birthdate", "place_of_birth", "place_of_birth.country", "nationalities", "nationalities[0]", "date_of_expiry", "issuing_authority", "issuing_country"},
+			err:   "invalid token: exp claim must be included in plaintext",
+		},
+		{
+			name:  "invalid date format",
+			vct:   sdjwtvc.PidVct,
+			paths: []string{"family_name", "given_name", "birthdate", "place_of_birth", "place_of_birth.country", "nationalities", "nationalities[0]", "date_of_expiry", "issuing_authority", "issuing_country"},
+			err:   "invalid token: claims do not match the schema: claim birthdate must be a valid date",
+		},
+		{
+			name: "required attribute missing",
+			vct:  sdjwtvc.PidVct,
+			modify: func(claims map[string]any) {
+				delete(claims, "date_of_expiry")
+				claims["birthdate"] = "1964-08-12"
+			},
+			// every disclosure is held, so date_of_expiry cannot have been withheld
+			paths: []string{"family_name", "given_name", "birthdate", "place_of_birth", "place_of_birth.country", "nationalities", "nationalities[0]", "issuing_authority", "issuing_country"},
+			err:   "invalid token: claims do not match the schema: claim date_of_expiry is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := map[string]any{}
+			for k, v := range claims {
+				c[k] = v
+			}
+			if tt.modify != nil {
+				tt.modify(c)
+			}
+			// issued without the pid profile, as a non-conforming issuer would
+			sdJwt, err := sdjwtvc.Issue(c, sdjwtvc.IssuanceOptions{
+				IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner, SelectivelyDisclosable: tt.paths},
+				Vct:             tt.vct,
+			})
+			require.NoError(t, err)
+
+			_, err = sdjwtvc.VerifyPid(sdJwt, sdjwtvc.PidVerificationOptions{})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package sdjwtvc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+)
+
+// RenderOptions configures how Render selects and fills an SVG template
+type RenderOptions struct {
+	// Fetcher retrieves the SVG template
+	Fetcher typemetadata.Fetcher
+	// Locale and FallbackLocale select the display entry whose templates are used, as for ConsentOptions
+	Locale         string
+	FallbackLocale string
+	// Orientation, ColorScheme and Contrast select between the templates of the display entry, e.g. portrait, dark or
+	// high. Templates which do not declare a property match any value
+	Orientation string
+	ColorScheme string
+	Contrast    string
+}
+
+var svgPlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Render fills an SVG template from the type metadata with the provided claims, e.g. the result of
+// SdJwt.GetDisclosedClaims. Each {{svg_id}} placeholder is replaced with the XML escaped value of the claim whose claim
+// metadata declares that svg_id. Placeholders for claims which were not disclosed, or which are not described by the
+// metadata, are left blank. When the template declares uri#integrity, the retrieved template is checked against it.
+func Render(ctx context.Context, claims map[string]any, metadata *typemetadata.TypeMetadata, opts RenderOptions) ([]byte, error) {
+	if metadata == nil {
+		return nil, errors.New("type metadata must be provided")
+	}
+	if opts.Fetcher == nil {
+		return nil, errors.New("a fetcher must be provided")
+	}
+
+	template, err := selectSvgTemplate(metadata, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	svg, err := opts.Fetcher.Fetch(ctx, template.Uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve svg template %s: %w", template.Uri, err)
+	}
+	if template.UriIntegrity != "" {
+		if err := typemetadata.CheckIntegrity(svg, template.UriIntegrity); err != nil {
+			return nil, fmt.Errorf("svg template %s failed the integrity check: %w", template.Uri, err)
+		}
+	}
+
+	values := map[string]string{}
+	for _, c := range metadata.Claims {
+		if c.SvgId == "" {
+			continue
+		}
+		value, err := svgValue(claims, c.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render claim %s: %w", c.Path, err)
+		}
+		values[c.SvgId] = value
+	}
+
+	var renderErr error
+	rendered := svgPlaceholder.ReplaceAllFunc(svg, func(placeholder []byte) []byte {
+		id := svgPlaceholder.FindSubmatch(placeholder)[1]
+		var escaped bytes.Buffer
+		if err := xml.EscapeText(&escaped, []byte(values[string(id)])); err != nil {
+			renderErr = err
+		}
+		return escaped.Bytes()
+	})
+	if renderErr != nil {
+		return nil, renderErr
+	}
+	return rendered, nil
+}
+
+// selectSvgTemplate returns the SVG template of the selected display entry matching the most requested properties.
+// Templates declaring a property with a value other than the one requested are not considered
+func selectSvgTemplate(metadata *typemetadata.TypeMetadata, opts RenderOptions) (*typemetadata.SvgTemplate, error) {
+	var displays []typemetadata.Display
+	var locales []string
+	for _, d := range metadata.Display {
+		if d.Rendering != nil && len(d.Rendering.SvgTemplates) > 0 {
+			displays = append(displays, d)
+			locales = append(locales, d.Locale)
+		}
+	}
+	i := pickLocale(locales, opts.Locale, opts.FallbackLocale)
+	if i == -1 {
+		return nil, fmt.Errorf("type metadata for %s has no svg templates", metadata.Vct)
+	}
+
+	var selected *typemetadata.SvgTemplate
+	best := -1
+	for _, template := range displays[i].Rendering.SvgTemplates {
+		properties := template.Properties
+		if properties == nil {
+			properties = &typemetadata.SvgTemplateProperties{}
+		}
+		score := 0
+		matches := true
+		for _, p := range [][2]string{
+			{properties.Orientation, opts.Orientation},
+			{properties.ColorScheme, opts.ColorScheme},
+			{properties.Contrast, opts.Contrast},
+		} {
+			switch {
+			case p[0] == "" || p[1] == "":
+			case p[0] == p[1]:
+				score++
+			default:
+				matches = false
+			}
+		}
+		if matches && score > best {
+			selected, best = &template, score
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("no svg template for %s matches the requested properties", metadata.Vct)
+	}
+	return selected, nil
+}
+
+// svgValue returns the text for the claims selected by the path, values of multiple array elements are joined with
+// a comma
+func svgValue(claims map[string]any, path go_sd_jwt.ClaimPath) (string, error) {
+	var parts []string
+	for _, concrete := range expandClaimPath(claims, path, nil) {
+		v := claimValue(claims, concrete)
+		switch value := v.(type) {
+		case nil:
+			continue
+		case string:
+			parts = append(parts, value)
+		default:
+			b, err := json.Marshal(value)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, string(b))
+		}
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// claimValue returns the value at a concrete claim path
+func claimValue(v any, path go_sd_jwt.ClaimPath) any {
+	for _, element := range path {
+		switch e := element.(type) {
+		case string:
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil
+			}
+			v = obj[e]
+		case int:
+			arr, ok := v.([]any)
+			if !ok || e >= len(arr) {
+				return nil
+			}
+			v = arr[e]
+		}
+	}
+	return v
+}
@@ -0,0 +1,194 @@
+package sdjwtvc_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	portraitTemplate  = `<svg xmlns="http://www.w3.org/2000/svg"><text>{{given_name}} {{ family_name }}</text><text>{{nationalities}}</text><text title="{{street}}">{{unknown}}</text></svg>`
+	landscapeTemplate = `<svg xmlns="http://www.w3.org/2000/svg" width="400"><text>{{family_name}}</text></svg>`
+	darkTemplate      = `<svg xmlns="http://www.w3.org/2000/svg" class="dark"><text>{{family_name}}</text></svg>`
+)
+
+func integrity(document string) string {
+	sum := sha256.Sum256([]byte(document))
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func renderMetadata() *typemetadata.TypeMetadata {
+	return &typemetadata.TypeMetadata{
+		Vct: identityVct,
+		Display: []typemetadata.Display{
+			{Locale: "de-DE", Name: "Identitätsnachweis"},
+			{Locale: "en-US", Name: "Identity Credential", Rendering: &typemetadata.Rendering{
+				SvgTemplates: []typemetadata.SvgTemplate{
+					{
+						Uri:          "https://credentials.example.com/portrait.svg",
+						UriIntegrity: integrity(portraitTemplate),
+						Properties:   &typemetadata.SvgTemplateProperties{Orientation: "portrait", ColorScheme: "light"},
+					},
+					{
+						Uri:        "https://credentials.example.com/landscape.svg",
+						Properties: &typemetadata.SvgTemplateProperties{Orientation: "landscape"},
+					},
+					{
+						Uri:        "https://credentials.example.com/dark.svg",
+						Properties: &typemetadata.SvgTemplateProperties{ColorScheme: "dark"},
+					},
+				},
+			}},
+		},
+		Claims: []typemetadata.ClaimMetadata{
+			{Path: go_sd_jwt.ClaimPath{"given_name"}, SvgId: "given_name"},
+			{Path: go_sd_jwt.ClaimPath{"family_name"}, SvgId: "family_name"},
+			{Path: go_sd_jwt.ClaimPath{"nationalities", nil}, SvgId: "nationalities"},
+			{Path: go_sd_jwt.ClaimPath{"address", "street_address"}, SvgId: "street"},
+		},
+	}
+}
+
+func renderFetcher() typemetadata.MapFetcher {
+	return typemetadata.MapFetcher{
+		"https://credentials.example.com/portrait.svg":  []byte(portraitTemplate),
+		"https://credentials.example.com/landscape.svg": []byte(landscapeTemplate),
+		"https://credentials.example.com/dark.svg":      []byte(darkTemplate),
+	}
+}
+
+func TestRender(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := credentialClaims()
+	claims["family_name"] = `Mustermann & <Söhne>`
+	claims["nationalities"] = []string{"DE", "AT"}
+	sdJwt, err := sdjwtvc.Issue(claims, sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			SelectivelyDisclosable: []string{"given_name", "family_name", "address.street_address"},
+		},
+		Vct: identityVct,
+	})
+	require.NoError(t, err)
+
+	// the holder withholds the street address
+	sdJwt.Disclosures, err = sdJwt.SelectDisclosures("given_name", "family_name")
+	require.NoError(t, err)
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	require.NoError(t, err)
+
+	svg, err := sdjwtvc.Render(context.Background(), disclosed, renderMetadata(), sdjwtvc.RenderOptions{
+		Fetcher:     renderFetcher(),
+		Locale:      "de-DE",
+		Orientation: "portrait",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `<svg xmlns="http://www.w3.org/2000/svg"><text>Erika Mustermann &amp; &lt;Söhne&gt;</text><text>DE, AT</text><text title=""></text></svg>`, string(svg))
+}
+
+func TestRender_TemplateSelection(t *testing.T) {
+	claims := map[string]any{"family_name": "Mustermann"}
+
+	tests := []struct {
+		name     string
+		opts     sdjwtvc.RenderOptions
+		expected string
+	}{
+		{
+			name:     "landscape",
+			opts:     sdjwtvc.RenderOptions{Orientation: "landscape"},
+			expected: `<svg xmlns="http://www.w3.org/2000/svg" width="400"><text>Mustermann</text></svg>`,
+		},
+		{
+			name:     "dark",
+			opts:     sdjwtvc.RenderOptions{ColorScheme: "dark"},
+			expected: `<svg xmlns="http://www.w3.org/2000/svg" class="dark"><text>Mustermann</text></svg>`,
+		},
+		{
+			name:     "first of equal matches",
+			opts:     sdjwtvc.RenderOptions{Orientation: "landscape", ColorScheme: "dark"},
+			expected: `<svg xmlns="http://www.w3.org/2000/svg" width="400"><text>Mustermann</text></svg>`,
+		},
+		{
+			name:     "no preference",
+			opts:     sdjwtvc.RenderOptions{},
+			expected: `<svg xmlns="http://www.w3.org/2000/svg"><text> Mustermann</text><text></text><text title=""></text></svg>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Fetcher = renderFetcher()
+			svg, err := sdjwtvc.Render(context.Background(), claims, renderMetadata(), tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(svg))
+		})
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	claims := map[string]any{"family_name": "Mustermann"}
+
+	tests := []struct {
+		name     string
+		metadata *typemetadata.TypeMetadata
+		fetcher  typemetadata.Fetcher
+		opts     sdjwtvc.RenderOptions
+		err      string
+	}{
+		{
+			name:     "no templates",
+			metadata: &typemetadata.TypeMetadata{Vct: identityVct, Display: []typemetadata.Display{{Locale: "en-US", Name: "Identity Credential"}}},
+			fetcher:  renderFetcher(),
+			err:      "type metadata for " + identityVct + " has no svg templates",
+		},
+		{
+			name: "no matching template",
+			metadata: func() *typemetadata.TypeMetadata {
+				metadata := renderMetadata()
+				metadata.Display[1].Rendering.SvgTemplates = metadata.Display[1].Rendering.SvgTemplates[:1]
+				return metadata
+			}(),
+			fetcher: renderFetcher(),
+			opts:    sdjwtvc.RenderOptions{Orientation: "landscape"},
+			err:     "no svg template for " + identityVct + " matches the requested properties",
+		},
+		{
+			name:     "integrity mismatch",
+			metadata: renderMetadata(),
+			fetcher: typemetadata.MapFetcher{
+				"https://credentials.example.com/portrait.svg": []byte(`<svg><script>alert(1)</script></svg>`),
+			},
+			err: "svg template https://credentials.example.com/portrait.svg failed the integrity check: document does not match the sha256 integrity metadata",
+		},
+		{
+			name:     "template not found",
+			metadata: renderMetadata(),
+			fetcher:  typemetadata.MapFetcher{},
+			opts:     sdjwtvc.RenderOptions{Orientation: "landscape"},
+			err:      "failed to retrieve svg template https://credentials.example.com/landscape.svg: no document found for https://credentials.example.com/landscape.svg",
+		},
+		{
+			name: "no fetcher",
+			err:  "type metadata must be provided",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Fetcher = tt.fetcher
+			_, err := sdjwtvc.Render(context.Background(), claims, tt.metadata, tt.opts)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package sdjwtvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/jsonschema"
+)
+
+// SchemaError is a single JSON schema failure at the claim path of the invalid value
+type SchemaError struct {
+	Path    go_sd_jwt.ClaimPath
+	Message string
+}
+
+func (s SchemaError) Error() string {
+	if len(s.Path) == 0 {
+		return "claims " + s.Message
+	}
+	return fmt.Sprintf("claim %s %s", s.Path, s.Message)
+}
+
+// SchemaErrors is returned when claims do not match a JSON schema and lists every failure found
+type SchemaErrors []SchemaError
+
+func (s SchemaErrors) Error() string {
+	messages := make([]string, len(s))
+	for i, err := range s {
+		messages[i] = err.Error()
+	}
+	return "claims do not match the schema: " + strings.Join(messages, ", ")
+}
+
+// ValidateClaims validates the complete claim set of an SD-JWT VC, as passed to Issue, against a JSON schema such as
+// TypeMetadata.Schema. A SchemaErrors is returned if the claims do not match the schema.
+// The supported keywords are type, enum, const, the string, number, object and array assertions (including the date,
+// date-time, email and uri formats), allOf, anyOf, oneOf, not, if/then/else and $ref to definitions within the schema.
+// An error which is not a SchemaErrors is returned if the schema uses any other keyword.
+func ValidateClaims(claims map[string]any, schema map[string]any) error {
+	return validateSchema(claims, schema, jsonschema.Options{})
+}
+
+// ValidateDisclosedClaims validates the claims of an SD-JWT as returned by SdJwt.GetDisclosedClaims against a JSON
+// schema. As the holder may withhold selectively disclosable claims, a required property missing from an object is not
+// a failure while the object has digests which were not disclosed, up to one missing property per undisclosed digest.
+// Likewise an array with undisclosed elements may have fewer items than the schema requires.
+// A SchemaErrors is returned if the disclosed claims do not match the schema.
+func ValidateDisclosedClaims(sdJwt *go_sd_jwt.SdJwt, schema map[string]any) error {
+	claims, err := sdJwt.GetDisclosedClaims()
+	if err != nil {
+		return err
+	}
+	tree, err := sdJwt.DisclosureTree()
+	if err != nil {
+		return err
+	}
+
+	w := &withheldWalker{
+		disclosed: map[string]*go_sd_jwt.DisclosureNode{},
+		objects:   map[string]int{},
+		arrays:    map[string]bool{},
+		missing:   map[string]map[string]bool{},
+	}
+	var index func(nodes []*go_sd_jwt.DisclosureNode)
+	index = func(nodes []*go_sd_jwt.DisclosureNode) {
+		for _, n := range nodes {
+			w.disclosed[n.Digest] = n
+			index(n.Children)
+		}
+	}
+	index(tree)
+	w.walk(sdJwt.Body, nil)
+
+	return validateSchema(claims, schema, jsonschema.Options{
+		OptionalProperty: func(path go_sd_jwt.ClaimPath, name string) bool {
+			if len(path) == 0 && slices.Contains(NonSelectivelyDisclosableClaims, name) {
+				return false
+			}
+			// an array whose elements were all withheld is absent from the disclosed claims
+			if w.arrays[path.Append(name).String()] {
+				return true
+			}
+			// each undisclosed digest can account for a single missing property
+			key := path.String()
+			missing := w.missing[key]
+			if missing[name] {
+				return true
+			}
+			if len(missing) >= w.objects[key] {
+				return false
+			}
+			if missing == nil {
+				missing = map[string]bool{}
+				w.missing[key] = missing
+			}
+			missing[name] = true
+			return true
+		},
+		OptionalItems: func(path go_sd_jwt.ClaimPath) bool {
+			return w.arrays[path.String()]
+		},
+	})
+}
+
+func validateSchema(claims map[string]any, schema map[string]any, opts jsonschema.Options) error {
+	normalised, err := normaliseClaims(claims)
+	if err != nil {
+		return err
+	}
+	failures, err := jsonschema.Validate(schema, normalised, opts)
+	if err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	errs := make(SchemaErrors, len(failures))
+	for i, f := range failures {
+		errs[i] = SchemaError{Path: f.Path, Message: f.Message}
+	}
+	return errs
+}
+
+// withheldWalker records the objects and arrays of the issuer-signed payload, and of the disclosures within it, which
+// contain digests that were not disclosed. Paths are those of the disclosed claims, in which undisclosed array elements
+// are not counted.
+type withheldWalker struct {
+	disclosed map[string]*go_sd_jwt.DisclosureNode
+	// objects holds the number of undisclosed digests in the _sd array of each object
+	objects map[string]int
+	// arrays holds the arrays containing undisclosed elements
+	arrays map[string]bool
+	// missing holds the required properties of each object accepted as withheld
+	missing map[string]map[string]bool
+}
+
+func (w *withheldWalker) walk(v any, path go_sd_jwt.ClaimPath) {
+	switch value := v.(type) {
+	case map[string]any:
+		sd, _ := value["_sd"].([]any)
+		for _, digest := range sd {
+			strDigest, _ := digest.(string)
+			n, ok := w.disclosed[strDigest]
+			if !ok || n.Disclosure.Key == nil {
+				w.objects[path.String()]++
+				continue
+			}
+			w.walk(n.Disclosure.Value, path.Append(*n.Disclosure.Key))
+		}
+		for k, child := range value {
+			if k != "_sd" && k != "_sd_alg" {
+				w.walk(child, path.Append(k))
+			}
+		}
+	case []any:
+		i := 0
+		for _, child := range value {
+			if m, ok := child.(map[string]any); ok && len(m) == 1 {
+				if digest, ok := m["..."].(string); ok {
+					n, ok := w.disclosed[digest]
+					if !ok {
+						w.arrays[path.String()] = true
+						continue
+					}
+					child = n.Disclosure.Value
+				}
+			}
+			w.walk(child, path.Append(i))
+			i++
+		}
+	}
+}
+
+// normaliseClaims returns the claims in their JSON form
+func normaliseClaims(claims map[string]any) (any, error) {
+	b, err := json.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal claims: %w", err)
+	}
+	var normalised any
+	if err := json.Unmarshal(b, &normalised); err != nil {
+		return nil, fmt.Errorf("failed to parse claims: %w", err)
+	}
+	return normalised, nil
+}
@@ -0,0 +1,337 @@
+package sdjwtvc_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const identitySchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"properties": {
+		"vct": {"type": "string"},
+		"iss": {"type": "string", "format": "uri"},
+		"given_name": {"type": "string", "minLength": 1},
+		"family_name": {"type": "string", "minLength": 1},
+		"birthdate": {"type": "string", "format": "date"},
+		"age_in_years": {"type": "integer", "minimum": 0},
+		"nationalities": {
+			"type": "array",
+			"minItems": 1,
+			"uniqueItems": true,
+			"items": {"type": "string", "pattern": "^[A-Z]{2}$"}
+		},
+		"address": {"$ref": "#/$defs/address"}
+	},
+	"required": ["vct", "iss", "given_name", "family_name", "nationalities"],
+	"$defs": {
+		"address": {
+			"type": "object",
+			"properties": {
+				"street_address": {"type": "string"},
+				"locality": {"type": "string"}
+			},
+			"required": ["street_address", "locality"],
+			"additionalProperties": false
+		}
+	}
+}`
+
+func schema(t *testing.T) map[string]any {
+	var s map[string]any
+	require.NoError(t, json.Unmarshal([]byte(identitySchema), &s))
+	return s
+}
+
+func schemaClaims() map[string]any {
+	claims := sdRulesClaims()
+	claims["vct"] = identityVct
+	return claims
+}
+
+func TestValidateClaims(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(claims map[string]any)
+		errs   []string
+	}{
+		{
+			name:   "valid",
+			modify: func(claims map[string]any) {},
+		},
+		{
+			name: "missing required claims",
+			modify: func(claims map[string]any) {
+				delete(claims, "given_name")
+				delete(claims, "nationalities")
+			},
+			errs: []string{"claim given_name is required", "claim nationalities is required"},
+		},
+		{
+			name: "nested failures",
+			modify: func(claims map[string]any) {
+				claims["address"] = map[string]any{"street_address": 17, "country": "DE"}
+			},
+			errs: []string{
+				"claim address.locality is required",
+				"claim address.country is not allowed",
+				"claim address.street_address must be of type string",
+			},
+		},
+		{
+			name: "array elements",
+			modify: func(claims map[string]any) {
+				claims["nationalities"] = []string{"DE", "Deutschland", "DE"}
+			},
+			errs: []string{"claim nationalities must not contain duplicate items", "claim nationalities[1] must match the pattern ^[A-Z]{2}$"},
+		},
+		{
+			name: "formats and numbers",
+			modify: func(claims map[string]any) {
+				claims["birthdate"] = "1964-13-12"
+				claims["age_in_years"] = 59.5
+			},
+			errs: []string{"claim age_in_years must be of type integer", "claim birthdate must be a valid date"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := schemaClaims()
+			tt.modify(claims)
+			err := sdjwtvc.ValidateClaims(claims, schema(t))
+			if tt.errs == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var schemaErrs sdjwtvc.SchemaErrors
+			require.True(t, errors.As(err, &schemaErrs))
+			var messages []string
+			for _, schemaErr := range schemaErrs {
+				messages = append(messages, schemaErr.Error())
+			}
+			assert.ElementsMatch(t, tt.errs, messages)
+		})
+	}
+}
+
+func TestValidateClaims_Applicators(t *testing.T) {
+	s := map[string]any{
+		"properties": map[string]any{
+			"document_number": map[string]any{
+				"anyOf": []any{
+					map[string]any{"type": "string"},
+					map[string]any{"type": "integer"},
+				},
+			},
+			"sex": map[string]any{
+				"oneOf": []any{
+					map[string]any{"enum": []any{1.0, 2.0}},
+					map[string]any{"enum": []any{2.0, 9.0}},
+				},
+			},
+			"status": map[string]any{"not": map[string]any{"const": "revoked"}},
+		},
+	}
+
+	assert.NoError(t, sdjwtvc.ValidateClaims(map[string]any{"document_number": 123, "sex": 1, "status": "valid"}, s))
+
+	err := sdjwtvc.ValidateClaims(map[string]any{"document_number": true, "sex": 2, "status": "revoked"}, s)
+	require.Error(t, err)
+	assert.Equal(t, "claims do not match the schema: "+
+		"claim document_number must match at least one schema in anyOf, "+
+		"claim sex must match exactly one schema in oneOf, matched 2, "+
+		"claim status must not match the schema in not", err.Error())
+}
+
+func TestValidateClaims_InvalidSchema(t *testing.T) {
+	err := sdjwtvc.ValidateClaims(map[string]any{"given_name": "Erika"}, map[string]any{"$ref": "https://example.com/schema.json"})
+	require.Error(t, err)
+	assert.Equal(t, "invalid schema: unsupported $ref https://example.com/schema.json, only references within the schema are supported", err.Error())
+
+	var schemaErrs sdjwtvc.SchemaErrors
+	assert.False(t, errors.As(err, &schemaErrs))
+
+	// unsupported keywords are not ignored
+	s := schema(t)
+	s["unevaluatedProperties"] = false
+	err = sdjwtvc.ValidateClaims(schemaClaims(), s)
+	require.Error(t, err)
+	assert.Equal(t, "invalid schema: unsupported keyword unevaluatedProperties at #", err.Error())
+}
+
+func TestValidateDisclosedClaims(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	sdJwt, err := sdjwtvc.Issue(sdRulesClaims(), sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			SelectivelyDisclosable: []string{"given_name", "nationalities[*]", "address.street_address"},
+		},
+		Vct: identityVct,
+	})
+	require.NoError(t, err)
+
+	// the holder withholds every disclosure, leaving required claims and array elements absent
+	withheld := *sdJwt
+	withheld.Disclosures = nil
+	assert.NoError(t, sdjwtvc.ValidateDisclosedClaims(&withheld, schema(t)))
+
+	// the schema still applies to the claims which are disclosed
+	s := schema(t)
+	s["properties"].(map[string]any)["given_name"].(map[string]any)["minLength"] = 10.0
+	err = sdjwtvc.ValidateDisclosedClaims(sdJwt, s)
+	require.Error(t, err)
+	assert.Equal(t, "claims do not match the schema: claim given_name must be at least 10 characters long", err.Error())
+
+	// required claims included in plaintext may not be absent
+	plaintext, err := sdjwtvc.Issue(sdRulesClaims(), sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+		Vct:             identityVct,
+	})
+	require.NoError(t, err)
+	delete(plaintext.Body, "family_name")
+	delete(plaintext.Body["address"].(map[string]any), "locality")
+	err = sdjwtvc.ValidateDisclosedClaims(plaintext, schema(t))
+	require.Error(t, err)
+	assert.Equal(t, "claims do not match the schema: claim family_name is required, claim address.locality is required", err.Error())
+}
+
+func TestValidateDisclosedClaims_MissingNotWithheld(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	sdJwt, err := sdjwtvc.Issue(sdRulesClaims(), sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			SelectivelyDisclosable: []string{"given_name", "address.street_address"},
+		},
+		Vct: identityVct,
+	})
+	require.NoError(t, err)
+	delete(sdJwt.Body["address"].(map[string]any), "locality")
+
+	// address contains digests, but the holder disclosed all of them so locality cannot have been withheld
+	err = sdjwtvc.ValidateDisclosedClaims(sdJwt, schema(t))
+	require.Error(t, err)
+	assert.Equal(t, "claims do not match the schema: claim address.locality is required", err.Error())
+
+	// a single undisclosed digest accounts for one missing property, not both
+	withheld := *sdJwt
+	withheld.Disclosures, err = sdJwt.SelectDisclosures("given_name")
+	require.NoError(t, err)
+	err = sdjwtvc.ValidateDisclosedClaims(&withheld, schema(t))
+	require.Error(t, err)
+	assert.Equal(t, "claims do not match the schema: claim address.locality is required", err.Error())
+
+	// registered claims which may not be selectively disclosed cannot have been withheld, while given_name can
+	withheld.Disclosures, err = sdJwt.SelectDisclosures("address.street_address")
+	require.NoError(t, err)
+	delete(withheld.Body, "iss")
+	err = sdjwtvc.ValidateDisclosedClaims(&withheld, schema(t))
+	require.Error(t, err)
+	assert.Equal(t, "claims do not match the schema: claim iss is required, claim address.locality is required", err.Error())
+}
+
+func TestIssue_Schema(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	metadata := &typemetadata.TypeMetadata{Vct: identityVct, Schema: schema(t)}
+
+	_, err = sdjwtvc.Issue(sdRulesClaims(), sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+		Vct:             identityVct,
+		TypeMetadata:    metadata,
+	})
+	require.NoError(t, err)
+
+	claims := sdRulesClaims()
+	delete(claims, "family_name")
+	_, err = sdjwtvc.Issue(claims, sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+		Vct:             identityVct,
+		TypeMetadata:    metadata,
+	})
+	require.Error(t, err)
+	assert.Equal(t, "invalid issuance: claims do not match the schema: claim family_name is required", err.Error())
+	var schemaErrs sdjwtvc.SchemaErrors
+	require.True(t, errors.As(err, &schemaErrs))
+	assert.Equal(t, go_sd_jwt.ClaimPath{"family_name"}, schemaErrs[0].Path)
+}
+
+func TestIssue_SchemaFinalClaims(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cnf, err := go_sd_jwt.NewJwkConfirmation(&holderKey.PublicKey)
+	require.NoError(t, err)
+
+	// iat, exp and cnf are added while issuing, the schema must be checked against the claims which are signed
+	s := schema(t)
+	s["required"] = append(s["required"].([]any), "iat", "exp", "cnf")
+	claims := sdRulesClaims()
+	delete(claims, "iat")
+	delete(claims, "exp")
+
+	opts := sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:   issuerSigner,
+			Cnf:      cnf,
+			Validity: go_sd_jwt.Validity{Lifetime: time.Hour},
+		},
+		Vct:          identityVct,
+		TypeMetadata: &typemetadata.TypeMetadata{Vct: identityVct, Schema: s},
+	}
+	_, err = sdjwtvc.Issue(claims, opts)
+	require.NoError(t, err)
+
+	opts.Cnf = nil
+	_, err = sdjwtvc.Issue(claims, opts)
+	require.Error(t, err)
+	assert.Equal(t, "invalid issuance: claims do not match the schema: claim cnf is required", err.Error())
+}
+
+func TestVerify_Schema(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	metadata := &typemetadata.TypeMetadata{Vct: identityVct, Schema: schema(t)}
+
+	sdJwt, err := sdjwtvc.Issue(sdRulesClaims(), sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			SelectivelyDisclosable: []string{"given_name", "address.locality"},
+		},
+		Vct:          identityVct,
+		TypeMetadata: metadata,
+	})
+	require.NoError(t, err)
+	sdJwt.Disclosures = nil
+
+	_, err = sdjwtvc.Verify(sdJwt, sdjwtvc.VerificationOptions{TypeMetadata: metadata, ValidateSchema: true})
+	require.NoError(t, err)
+
+	// a schema which the issued claims do not match, e.g. from newer type metadata
+	s := schema(t)
+	s["properties"].(map[string]any)["family_name"].(map[string]any)["maxLength"] = 3.0
+	_, err = sdjwtvc.Verify(sdJwt, sdjwtvc.VerificationOptions{
+		TypeMetadata:   &typemetadata.TypeMetadata{Vct: identityVct, Schema: s},
+		ValidateSchema: true,
+	})
+	require.Error(t, err)
+	assert.Equal(t, "invalid token: claims do not match the schema: claim family_name must be at most 3 characters long", err.Error())
+}
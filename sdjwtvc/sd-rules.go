@@ -0,0 +1,175 @@
+package sdjwtvc
+
+import (
+	"fmt"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+)
+
+// applySdRules returns the selectively disclosable claim paths for the claims, adding the concrete paths of every claim
+// the type metadata requires to always be selectively disclosable. An error is returned if any of the provided paths
+// selects a claim the type metadata requires to never be selectively disclosable.
+func applySdRules(claims map[string]any, paths []string, metadata *typemetadata.TypeMetadata) ([]string, error) {
+	normalised, err := normaliseClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := make([]go_sd_jwt.ClaimPath, len(paths))
+	for i, path := range paths {
+		if parsed[i], err = go_sd_jwt.ParseClaimPath(path); err != nil {
+			return nil, err
+		}
+	}
+
+	result := append([]string(nil), paths...)
+	for _, c := range metadata.Claims {
+		switch c.SdRule() {
+		case typemetadata.SdNever:
+			for i, path := range parsed {
+				if overlaps(c.Path, path) {
+					return nil, fmt.Errorf("claim %s must never be selectively disclosable but is selected by %s", c.Path, paths[i])
+				}
+			}
+		case typemetadata.SdAlways:
+			for _, concrete := range expandClaimPath(normalised, c.Path, nil) {
+				if !selected(parsed, concrete) {
+					parsed = append(parsed, concrete)
+					result = append(result, concrete.String())
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// checkSdRules returns an error if a claim the type metadata requires to never be selectively disclosable is disclosed
+// through a disclosure, or a claim it requires to always be selectively disclosable is included in plaintext
+func checkSdRules(sdJwt *go_sd_jwt.SdJwt, metadata *typemetadata.TypeMetadata) error {
+	tree, err := sdJwt.DisclosureTree()
+	if err != nil {
+		return err
+	}
+
+	var disclosed, plaintext []go_sd_jwt.ClaimPath
+	plaintextPaths(sdJwt.Body, nil, &plaintext)
+	var walk func(nodes []*go_sd_jwt.DisclosureNode)
+	walk = func(nodes []*go_sd_jwt.DisclosureNode) {
+		for _, n := range nodes {
+			disclosed = append(disclosed, n.Path)
+			plaintextPaths(n.Disclosure.Value, n.Path, &plaintext)
+			walk(n.Children)
+		}
+	}
+	walk(tree)
+
+	for _, c := range metadata.Claims {
+		switch c.SdRule() {
+		case typemetadata.SdNever:
+			for _, path := range disclosed {
+				if c.Path.Matches(path) {
+					return fmt.Errorf("claim %s must never be selectively disclosable", path)
+				}
+			}
+		case typemetadata.SdAlways:
+			for _, path := range plaintext {
+				if c.Path.Matches(path) {
+					return fmt.Errorf("claim %s must always be selectively disclosable", path)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// plaintextPaths appends the paths of the members and elements of v which are included in plaintext, skipping the
+// digests of selectively disclosable claims and array elements
+func plaintextPaths(v any, path go_sd_jwt.ClaimPath, paths *[]go_sd_jwt.ClaimPath) {
+	switch value := v.(type) {
+	case map[string]any:
+		for k, child := range value {
+			if k == "_sd" || k == "_sd_alg" {
+				continue
+			}
+			*paths = append(*paths, path.Append(k))
+			plaintextPaths(child, path.Append(k), paths)
+		}
+	case []any:
+		for i, child := range value {
+			if m, ok := child.(map[string]any); ok && len(m) == 1 {
+				if _, ok := m["..."]; ok {
+					continue
+				}
+			}
+			*paths = append(*paths, path.Append(i))
+			plaintextPaths(child, path.Append(i), paths)
+		}
+	}
+}
+
+// expandClaimPath returns the concrete paths of the claims in v selected by the pattern
+func expandClaimPath(v any, pattern, path go_sd_jwt.ClaimPath) []go_sd_jwt.ClaimPath {
+	if len(pattern) == 0 {
+		return []go_sd_jwt.ClaimPath{path}
+	}
+	switch element := pattern[0].(type) {
+	case string:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		child, ok := obj[element]
+		if !ok {
+			return nil
+		}
+		return expandClaimPath(child, pattern[1:], path.Append(element))
+	case int:
+		arr, ok := v.([]any)
+		if !ok || element >= len(arr) {
+			return nil
+		}
+		return expandClaimPath(arr[element], pattern[1:], path.Append(element))
+	default:
+		arr, ok := v.([]any)
+		if !ok {
+			return nil
+		}
+		var paths []go_sd_jwt.ClaimPath
+		for i := range arr {
+			paths = append(paths, expandClaimPath(arr[i], pattern[1:], path.Append(i))...)
+		}
+		return paths
+	}
+}
+
+// overlaps reports whether two claim path patterns can select the same claim
+func overlaps(a, b go_sd_jwt.ClaimPath) bool {
+	return a.Matches(concrete(b, a)) || b.Matches(concrete(a, b))
+}
+
+// concrete replaces the wildcards of p with the array index at the same position of other, or 0
+func concrete(p, other go_sd_jwt.ClaimPath) go_sd_jwt.ClaimPath {
+	c := make(go_sd_jwt.ClaimPath, len(p))
+	for i, element := range p {
+		c[i] = element
+		if element == nil {
+			c[i] = 0
+			if i < len(other) {
+				if index, ok := other[i].(int); ok {
+					c[i] = index
+				}
+			}
+		}
+	}
+	return c
+}
+
+func selected(paths []go_sd_jwt.ClaimPath, path go_sd_jwt.ClaimPath) bool {
+	for _, p := range paths {
+		if p.Matches(path) {
+			return true
+		}
+	}
+	return false
+}
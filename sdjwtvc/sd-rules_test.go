@@ -0,0 +1,171 @@
+package sdjwtvc_test
+
+import (
+	"testing"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const identityVct = "https://credentials.example.com/identity_credential"
+
+func sdRulesMetadata() *typemetadata.TypeMetadata {
+	return &typemetadata.TypeMetadata{
+		Vct: identityVct,
+		Claims: []typemetadata.ClaimMetadata{
+			{Path: go_sd_jwt.ClaimPath{"given_name"}, Sd: typemetadata.SdAlways},
+			{Path: go_sd_jwt.ClaimPath{"nationalities", nil}, Sd: typemetadata.SdAlways},
+			{Path: go_sd_jwt.ClaimPath{"address", "street_address"}, Sd: typemetadata.SdAlways},
+			{Path: go_sd_jwt.ClaimPath{"family_name"}, Sd: typemetadata.SdNever},
+			{Path: go_sd_jwt.ClaimPath{"address", "locality"}},
+			{Path: go_sd_jwt.ClaimPath{"birthdate"}, Sd: typemetadata.SdAlways},
+		},
+	}
+}
+
+func sdRulesClaims() map[string]any {
+	claims := credentialClaims()
+	claims["iss"] = "https://issuer.example.com"
+	claims["nationalities"] = []string{"DE", "AT"}
+	return claims
+}
+
+func TestIssue_SdRules(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	sdJwt, err := sdjwtvc.Issue(sdRulesClaims(), sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			SelectivelyDisclosable: []string{"address.locality", "nationalities[1]"},
+		},
+		Vct:          identityVct,
+		TypeMetadata: sdRulesMetadata(),
+	})
+	require.NoError(t, err)
+
+	tree, err := sdJwt.DisclosureTree()
+	require.NoError(t, err)
+	var paths []string
+	for _, n := range tree {
+		paths = append(paths, n.Path.String())
+	}
+	assert.ElementsMatch(t, []string{"given_name", "nationalities[0]", "nationalities[1]", "address.street_address", "address.locality"}, paths)
+	assert.Equal(t, "Mustermann", sdJwt.Body["family_name"])
+
+	credential, err := sdjwtvc.Verify(sdJwt, sdjwtvc.VerificationOptions{
+		VerificationOptions: go_sd_jwt.VerificationOptions{IssuerKey: issuerSigner.Public()},
+		TypeMetadata:        sdRulesMetadata(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"DE", "AT"}, credential.Claims["nationalities"])
+}
+
+func TestIssue_SdRules_Errors(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		paths    []string
+		metadata *typemetadata.TypeMetadata
+		err      string
+	}{
+		{
+			name:     "never claim selected",
+			paths:    []string{"family_name"},
+			metadata: sdRulesMetadata(),
+			err:      "invalid issuance: claim family_name must never be selectively disclosable but is selected by family_name",
+		},
+		{
+			name:  "never array element selected by a wildcard",
+			paths: []string{"nationalities[*]"},
+			metadata: &typemetadata.TypeMetadata{
+				Vct:    identityVct,
+				Claims: []typemetadata.ClaimMetadata{{Path: go_sd_jwt.ClaimPath{"nationalities", 0}, Sd: typemetadata.SdNever}},
+			},
+			err: "invalid issuance: claim nationalities[0] must never be selectively disclosable but is selected by nationalities[*]",
+		},
+		{
+			name: "always on a registered claim",
+			metadata: &typemetadata.TypeMetadata{
+				Vct:    identityVct,
+				Claims: []typemetadata.ClaimMetadata{{Path: go_sd_jwt.ClaimPath{"exp"}, Sd: typemetadata.SdAlways}},
+			},
+			err: "invalid issuance: claim exp must not be selectively disclosable in an SD-JWT VC",
+		},
+		{
+			name:     "other vct",
+			metadata: &typemetadata.TypeMetadata{Vct: "https://credentials.example.com/other"},
+			err:      "invalid issuance: type metadata is for vct https://credentials.example.com/other",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sdjwtvc.Issue(sdRulesClaims(), sdjwtvc.IssuanceOptions{
+				IssuanceOptions: go_sd_jwt.IssuanceOptions{
+					Signer:                 issuerSigner,
+					SelectivelyDisclosable: tt.paths,
+				},
+				Vct:          identityVct,
+				TypeMetadata: tt.metadata,
+			})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestVerify_SdRules(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		paths []string
+		err   string
+	}{
+		{
+			name:  "never claim disclosed",
+			paths: []string{"given_name", "family_name", "nationalities[*]", "address.street_address"},
+			err:   "invalid token: claim family_name must never be selectively disclosable",
+		},
+		{
+			name:  "always claim in plaintext",
+			paths: []string{"given_name", "nationalities[*]"},
+			err:   "invalid token: claim address.street_address must always be selectively disclosable",
+		},
+		{
+			name:  "always array element in plaintext",
+			paths: []string{"given_name", "nationalities[0]", "address.street_address"},
+			err:   "invalid token: claim nationalities[1] must always be selectively disclosable",
+		},
+		{
+			name:  "always claim in plaintext within a disclosure",
+			paths: []string{"given_name", "nationalities[*]", "address"},
+			err:   "invalid token: claim address.street_address must always be selectively disclosable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// issued without the type metadata, as an issuer unaware of the sd rules would
+			sdJwt, err := sdjwtvc.Issue(sdRulesClaims(), sdjwtvc.IssuanceOptions{
+				IssuanceOptions: go_sd_jwt.IssuanceOptions{
+					Signer:                 issuerSigner,
+					SelectivelyDisclosable: tt.paths,
+				},
+				Vct: identityVct,
+			})
+			require.NoError(t, err)
+
+			_, err = sdjwtvc.Verify(sdJwt, sdjwtvc.VerificationOptions{TypeMetadata: sdRulesMetadata()})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
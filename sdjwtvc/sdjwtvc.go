@@ -0,0 +1,65 @@
+// Package sdjwtvc implements the SD-JWT VC profile (draft-ietf-oauth-sd-jwt-vc) on top of go_sd_jwt.
+// An SD-JWT VC carries a vct claim identifying the credential type, uses the dc+sd-jwt typ header and never makes
+// the registered claims iss, nbf, exp, cnf, vct, vct#integrity or status selectively disclosable.
+package sdjwtvc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/utils"
+)
+
+const (
+	// Typ is the typ header of an SD-JWT VC
+	Typ = "dc+sd-jwt"
+	// LegacyTyp is the typ header used by earlier drafts of SD-JWT VC, accepted for compatibility
+	LegacyTyp = "vc+sd-jwt"
+)
+
+// NonSelectivelyDisclosableClaims lists the registered claims which must always be included in plaintext
+var NonSelectivelyDisclosableClaims = []string{"iss", "nbf", "exp", "cnf", "vct", "vct#integrity", "status"}
+
+func validTyp(typ string) bool {
+	return typ == Typ || typ == LegacyTyp
+}
+
+// validateRegisteredClaims checks the types of the registered claims of an SD-JWT VC
+func validateRegisteredClaims(claims map[string]any) error {
+	vct, ok := claims["vct"]
+	if !ok {
+		return errors.New("vct claim is required")
+	}
+	if s, ok := vct.(string); !ok || s == "" {
+		return errors.New("vct claim must be a non-empty string")
+	}
+
+	for _, name := range []string{"vct#integrity", "iss", "sub"} {
+		if v, ok := claims[name]; ok {
+			if _, ok := v.(string); !ok {
+				return fmt.Errorf("%s claim must be a string", name)
+			}
+		}
+	}
+
+	for _, name := range []string{"iat", "nbf", "exp"} {
+		if v, ok := claims[name]; ok {
+			if _, ok := utils.NumericDate(v); !ok {
+				return fmt.Errorf("%s claim must be a numeric date", name)
+			}
+		}
+	}
+
+	if v, ok := claims["cnf"]; ok {
+		if _, ok := v.(map[string]any); !ok {
+			return errors.New("cnf claim must be an object")
+		}
+	}
+
+	if v, ok := claims["status"]; ok {
+		if _, err := parseStatus(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package sdjwtvc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/utils"
+)
+
+// Status is the status claim of an SD-JWT VC, referencing the mechanisms through which the status of the credential
+// can be checked
+type Status struct {
+	// StatusList references an entry in a Token Status List (draft-ietf-oauth-status-list)
+	StatusList *StatusListReference
+	// Other contains any other status mechanisms by name, each of which must be an object
+	Other map[string]any
+}
+
+// StatusListReference is the index of the credential in the status list published at Uri
+type StatusListReference struct {
+	Idx int64
+	Uri string
+}
+
+func (s *Status) claim() map[string]any {
+	claim := make(map[string]any, len(s.Other)+1)
+	for k, v := range s.Other {
+		claim[k] = v
+	}
+	if s.StatusList != nil {
+		claim["status_list"] = map[string]any{
+			"idx": s.StatusList.Idx,
+			"uri": s.StatusList.Uri,
+		}
+	}
+	return claim
+}
+
+// parseStatus parses and validates the structure of a status claim
+func parseStatus(v any) (*Status, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, errors.New("status claim must be an object")
+	}
+	if len(m) == 0 {
+		return nil, errors.New("status claim must contain at least one status mechanism")
+	}
+
+	status := &Status{}
+	for name, value := range m {
+		mechanism, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("status mechanism %s must be an object", name)
+		}
+		if name != "status_list" {
+			if status.Other == nil {
+				status.Other = map[string]any{}
+			}
+			status.Other[name] = mechanism
+			continue
+		}
+
+		idx, ok := integer(mechanism["idx"])
+		if !ok || idx < 0 {
+			return nil, errors.New("status_list idx must be a non-negative integer")
+		}
+		uri, ok := mechanism["uri"].(string)
+		if !ok || uri == "" {
+			return nil, errors.New("status_list uri must be a non-empty string")
+		}
+		status.StatusList = &StatusListReference{Idx: idx, Uri: uri}
+	}
+	return status, nil
+}
+
+// integer returns the value of a claim which must be a whole number
+func integer(v any) (int64, bool) {
+	if n, ok := v.(json.Number); ok {
+		i, err := n.Int64()
+		return i, err == nil
+	}
+	n, ok := utils.NumericDate(v)
+	if !ok || n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(n), true
+}
@@ -0,0 +1,110 @@
+package typemetadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// DefaultMaxSize is the largest document HTTPFetcher retrieves when MaxSize is not set
+const DefaultMaxSize = 1 << 20
+
+// Fetcher retrieves the document identified by a URI, e.g. a type metadata document for a vct or an SVG template
+type Fetcher interface {
+	Fetch(ctx context.Context, uri string) ([]byte, error)
+}
+
+// FetcherFunc adapts a function to a Fetcher
+type FetcherFunc func(ctx context.Context, uri string) ([]byte, error)
+
+func (f FetcherFunc) Fetch(ctx context.Context, uri string) ([]byte, error) {
+	return f(ctx, uri)
+}
+
+// MapFetcher is an in-memory Fetcher returning the document stored for each URI, for tests and for types which are
+// known in advance
+type MapFetcher map[string][]byte
+
+func (m MapFetcher) Fetch(_ context.Context, uri string) ([]byte, error) {
+	document, ok := m[uri]
+	if !ok {
+		return nil, fmt.Errorf("no document found for %s", uri)
+	}
+	return document, nil
+}
+
+// HTTPFetcher retrieves documents with HTTP GET requests. Only https URIs are fetched, including the targets of any
+// redirects.
+type HTTPFetcher struct {
+	// Client is used for requests, defaults to http.DefaultClient
+	Client *http.Client
+	// MaxSize is the largest document which is retrieved in bytes, defaults to DefaultMaxSize
+	MaxSize int64
+}
+
+func (h HTTPFetcher) Fetch(ctx context.Context, uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uri %s: %w", uri, err)
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("uri %s must use https", uri)
+	}
+
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	client = httpsOnly(client)
+	maxSize := h.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", uri, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: unexpected status %d", uri, resp.StatusCode)
+	}
+
+	document, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", uri, err)
+	}
+	if int64(len(document)) > maxSize {
+		return nil, fmt.Errorf("document %s exceeds the maximum size of %d bytes", uri, maxSize)
+	}
+	return document, nil
+}
+
+// httpsOnly returns a copy of client which refuses to follow redirects to anything other than https URIs, on top of
+// the client's own redirect policy
+func httpsOnly(client *http.Client) *http.Client {
+	c := *client
+	checkRedirect := client.CheckRedirect
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("redirect to %s must use https", req.URL)
+		}
+		if checkRedirect != nil {
+			return checkRedirect(req, via)
+		}
+		// the default policy of http.Client
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &c
+}
@@ -0,0 +1,89 @@
+package typemetadata_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPFetcher(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/identity_credential":
+			_, _ = w.Write([]byte(`{"vct":"identity_credential"}`))
+		case "/large":
+			_, _ = w.Write(make([]byte, 64))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := typemetadata.HTTPFetcher{Client: server.Client(), MaxSize: 32}
+
+	document, err := fetcher.Fetch(context.Background(), server.URL+"/identity_credential")
+	require.NoError(t, err)
+	assert.Equal(t, `{"vct":"identity_credential"}`, string(document))
+
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/unknown")
+	require.Error(t, err)
+	assert.Equal(t, "failed to fetch "+server.URL+"/unknown: unexpected status 404", err.Error())
+
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/large")
+	require.Error(t, err)
+	assert.Equal(t, "document "+server.URL+"/large exceeds the maximum size of 32 bytes", err.Error())
+
+	_, err = fetcher.Fetch(context.Background(), "http://credentials.example.com/identity_credential")
+	require.Error(t, err)
+	assert.Equal(t, "uri http://credentials.example.com/identity_credential must use https", err.Error())
+}
+
+func TestHTTPFetcher_Redirects(t *testing.T) {
+	insecure := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"vct":"identity_credential"}`))
+	}))
+	defer insecure.Close()
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, server.URL+"/identity_credential", http.StatusFound)
+		case "/downgrade":
+			http.Redirect(w, r, insecure.URL+"/identity_credential", http.StatusFound)
+		case "/identity_credential":
+			_, _ = w.Write([]byte(`{"vct":"identity_credential"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := typemetadata.HTTPFetcher{Client: server.Client()}
+
+	document, err := fetcher.Fetch(context.Background(), server.URL+"/moved")
+	require.NoError(t, err)
+	assert.Equal(t, `{"vct":"identity_credential"}`, string(document))
+
+	_, err = fetcher.Fetch(context.Background(), server.URL+"/downgrade")
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "redirect to "+insecure.URL+"/identity_credential must use https")
+	assert.Nil(t, server.Client().CheckRedirect, "the provided client should not be modified")
+}
+
+func TestMapFetcher(t *testing.T) {
+	fetcher := typemetadata.MapFetcher{"https://credentials.example.com/a": []byte(`{}`)}
+
+	document, err := fetcher.Fetch(context.Background(), "https://credentials.example.com/a")
+	require.NoError(t, err)
+	assert.Equal(t, `{}`, string(document))
+
+	_, err = fetcher.Fetch(context.Background(), "https://credentials.example.com/b")
+	require.Error(t, err)
+	assert.Equal(t, "no document found for https://credentials.example.com/b", err.Error())
+}
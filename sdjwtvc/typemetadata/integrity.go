@@ -0,0 +1,59 @@
+package typemetadata
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// integrityAlgorithms lists the hash algorithms supported in integrity metadata from weakest to strongest
+var integrityAlgorithms = []struct {
+	name string
+	hash func() hash.Hash
+}{
+	{name: "sha256", hash: sha256.New},
+	{name: "sha384", hash: sha512.New384},
+	{name: "sha512", hash: sha512.New},
+}
+
+// CheckIntegrity checks a document against W3C Subresource Integrity metadata, e.g. the value of a vct#integrity
+// claim. The metadata is a space separated list of <alg>-<base64 digest> values. As in SRI, only the values using the
+// strongest of the supported algorithms (sha256, sha384 and sha512) are considered, and the document must match one
+// of them.
+func CheckIntegrity(document []byte, integrity string) error {
+	values := map[string][]string{}
+	strongest := -1
+	for _, token := range strings.Fields(integrity) {
+		alg, value, ok := strings.Cut(token, "-")
+		if !ok {
+			continue
+		}
+		// options following a '?' are reserved and ignored
+		value, _, _ = strings.Cut(value, "?")
+		for i, a := range integrityAlgorithms {
+			if a.name == alg {
+				values[alg] = append(values[alg], value)
+				strongest = max(strongest, i)
+			}
+		}
+	}
+	if strongest == -1 {
+		return errors.New("integrity metadata contains no supported hash algorithm")
+	}
+
+	alg := integrityAlgorithms[strongest]
+	h := alg.hash()
+	h.Write(document)
+	digest := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	for _, expected := range values[alg.name] {
+		if subtle.ConstantTimeCompare([]byte(digest), []byte(expected)) == 1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("document does not match the %s integrity metadata", alg.name)
+}
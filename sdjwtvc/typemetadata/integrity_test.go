@@ -0,0 +1,63 @@
+package typemetadata_test
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"testing"
+
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sri returns the sha256 subresource integrity metadata for a document
+func sri(document []byte) string {
+	sum := sha256.Sum256(document)
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	document := []byte(`{"vct":"https://credentials.example.com/identity_credential"}`)
+	sum384 := sha512.Sum384(document)
+	sha384 := "sha384-" + base64.StdEncoding.EncodeToString(sum384[:])
+	other := sri([]byte("other"))
+
+	tests := []struct {
+		name      string
+		integrity string
+		err       string
+	}{
+		{name: "sha256", integrity: sri(document)},
+		{name: "sha384", integrity: sha384},
+		{name: "any of the values of the strongest algorithm", integrity: other + " " + sri(document)},
+		{name: "options are ignored", integrity: sri(document) + "?ct=application/json"},
+		{name: "unsupported algorithms are ignored", integrity: "md5-abc " + sri(document)},
+		{
+			name:      "mismatch",
+			integrity: other,
+			err:       "document does not match the sha256 integrity metadata",
+		},
+		{
+			name:      "only the strongest algorithm is considered",
+			integrity: sri(document) + " sha384-" + base64.StdEncoding.EncodeToString(make([]byte, 48)),
+			err:       "document does not match the sha384 integrity metadata",
+		},
+		{
+			name:      "no supported algorithm",
+			integrity: "md5-abc",
+			err:       "integrity metadata contains no supported hash algorithm",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := typemetadata.CheckIntegrity(document, tt.integrity)
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
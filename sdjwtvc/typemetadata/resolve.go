@@ -0,0 +1,201 @@
+package typemetadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+)
+
+// DefaultMaxDepth is the longest extends chain a Resolver follows when MaxDepth is not set
+const DefaultMaxDepth = 8
+
+// Resolver retrieves type metadata documents through a Fetcher
+type Resolver struct {
+	// Fetcher retrieves type metadata documents by vct and JSON schemas by schema_uri
+	Fetcher Fetcher
+	// MaxDepth is the maximum number of types which may be extended, defaults to DefaultMaxDepth
+	MaxDepth int
+}
+
+// Resolve returns the type metadata for vct merged with the metadata of every type it extends.
+// When integrity is not empty, the document retrieved for vct is checked against it. The document of each extended
+// type is checked against the extends#integrity of the type extending it, and a schema_uri is resolved into Schema
+// and checked against schema_uri#integrity. An error is returned if the extends chain contains a cycle or is longer
+// than MaxDepth.
+func (r *Resolver) Resolve(ctx context.Context, vct, integrity string) (*TypeMetadata, error) {
+	if r.Fetcher == nil {
+		return nil, errors.New("a fetcher must be provided")
+	}
+	maxDepth := r.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	return r.resolve(ctx, vct, integrity, map[string]bool{}, maxDepth)
+}
+
+// ResolveSdJwt returns the type metadata for the vct claim of the SD-JWT VC, checked against its vct#integrity claim
+func (r *Resolver) ResolveSdJwt(ctx context.Context, sdJwt *go_sd_jwt.SdJwt) (*TypeMetadata, error) {
+	vct, ok := sdJwt.Body["vct"].(string)
+	if !ok || vct == "" {
+		return nil, errors.New("sd-jwt does not contain a vct claim")
+	}
+	integrity, _ := sdJwt.Body["vct#integrity"].(string)
+	return r.Resolve(ctx, vct, integrity)
+}
+
+func (r *Resolver) resolve(ctx context.Context, vct, integrity string, seen map[string]bool, depth int) (*TypeMetadata, error) {
+	if seen[vct] {
+		return nil, fmt.Errorf("extends chain contains a cycle at %s", vct)
+	}
+	seen[vct] = true
+
+	metadata, err := r.fetch(ctx, vct, integrity)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.Vct != vct {
+		return nil, fmt.Errorf("type metadata retrieved for %s has vct %s", vct, metadata.Vct)
+	}
+	for _, c := range metadata.Claims {
+		if rule := c.SdRule(); rule != SdAlways && rule != SdAllowed && rule != SdNever {
+			return nil, fmt.Errorf("type metadata for %s has an invalid sd value %s for claim %s", vct, c.Sd, c.Path)
+		}
+	}
+
+	if metadata.SchemaUri != "" {
+		if metadata.Schema != nil {
+			return nil, fmt.Errorf("type metadata for %s must not contain both schema and schema_uri", vct)
+		}
+		metadata.Schema, err = r.fetchSchema(ctx, metadata.SchemaUri, metadata.SchemaUriIntegrity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if metadata.Extends == "" {
+		return metadata, nil
+	}
+	if depth == 0 {
+		return nil, errors.New("extends chain exceeds the maximum depth")
+	}
+	parent, err := r.resolve(ctx, metadata.Extends, metadata.ExtendsIntegrity, seen, depth-1)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSdOverrides(parent, metadata); err != nil {
+		return nil, err
+	}
+	return Merge(parent, metadata), nil
+}
+
+// checkSdOverrides returns an error if the child type changes the sd property of a claim which the parent type
+// declares as always or never selectively disclosable
+func checkSdOverrides(parent, child *TypeMetadata) error {
+	rules := make(map[string]string, len(parent.Claims))
+	for _, c := range parent.Claims {
+		rules[c.Path.String()] = c.SdRule()
+	}
+	for _, c := range child.Claims {
+		rule, ok := rules[c.Path.String()]
+		if !ok || rule == SdAllowed || c.Sd == "" {
+			continue
+		}
+		if c.Sd != rule {
+			return fmt.Errorf("type metadata for %s changes the sd value of claim %s from %s to %s", child.Vct, c.Path, rule, c.Sd)
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) fetch(ctx context.Context, vct, integrity string) (*TypeMetadata, error) {
+	document, err := r.Fetcher.Fetch(ctx, vct)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve type metadata for %s: %w", vct, err)
+	}
+	if integrity != "" {
+		if err := CheckIntegrity(document, integrity); err != nil {
+			return nil, fmt.Errorf("type metadata for %s failed the integrity check: %w", vct, err)
+		}
+	}
+
+	var metadata TypeMetadata
+	if err := json.Unmarshal(document, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid type metadata for %s: %w", vct, err)
+	}
+	return &metadata, nil
+}
+
+func (r *Resolver) fetchSchema(ctx context.Context, uri, integrity string) (map[string]any, error) {
+	document, err := r.Fetcher.Fetch(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve schema %s: %w", uri, err)
+	}
+	if integrity != "" {
+		if err := CheckIntegrity(document, integrity); err != nil {
+			return nil, fmt.Errorf("schema %s failed the integrity check: %w", uri, err)
+		}
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(document, &schema); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", uri, err)
+	}
+	return schema, nil
+}
+
+// Merge returns the type metadata of child merged over the type metadata of the parent type it extends.
+// Name, description and schema are inherited when the child does not set them, display entries are merged by locale
+// and claim metadata is merged by claim path, with the entries of the child replacing those of the parent. A child entry
+// without an sd property inherits the sd property of the parent entry.
+func Merge(parent, child *TypeMetadata) *TypeMetadata {
+	merged := *child
+
+	if merged.Name == "" {
+		merged.Name = parent.Name
+	}
+	if merged.Description == "" {
+		merged.Description = parent.Description
+	}
+	if merged.Schema == nil {
+		merged.Schema = parent.Schema
+		merged.SchemaUri = parent.SchemaUri
+		merged.SchemaUriIntegrity = parent.SchemaUriIntegrity
+	}
+
+	merged.Display = append([]Display(nil), child.Display...)
+	childLocales := map[string]bool{}
+	for _, d := range child.Display {
+		childLocales[d.Locale] = true
+	}
+	for _, d := range parent.Display {
+		if !childLocales[d.Locale] {
+			merged.Display = append(merged.Display, d)
+		}
+	}
+
+	merged.Claims = nil
+	childClaims := map[string]ClaimMetadata{}
+	for _, c := range child.Claims {
+		childClaims[c.Path.String()] = c
+	}
+	for _, c := range parent.Claims {
+		if override, ok := childClaims[c.Path.String()]; ok {
+			if override.Sd == "" {
+				override.Sd = c.Sd
+			}
+			merged.Claims = append(merged.Claims, override)
+			delete(childClaims, c.Path.String())
+			continue
+		}
+		merged.Claims = append(merged.Claims, c)
+	}
+	for _, c := range child.Claims {
+		if _, ok := childClaims[c.Path.String()]; ok {
+			merged.Claims = append(merged.Claims, c)
+		}
+	}
+	return &merged
+}
@@ -0,0 +1,242 @@
+package typemetadata_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	baseVct     = "https://credentials.example.com/identity_credential"
+	extendedVct = "https://credentials.example.com/extended_identity_credential"
+)
+
+var baseMetadata = []byte(`{
+  "vct": "https://credentials.example.com/identity_credential",
+  "name": "Identity Credential",
+  "description": "A basic identity credential",
+  "display": [
+    {"locale": "en-US", "name": "Identity Credential"},
+    {"locale": "de-DE", "name": "Identitätsnachweis"}
+  ],
+  "claims": [
+    {"path": ["given_name"], "display": [{"locale": "en-US", "label": "Given Name"}]},
+    {"path": ["family_name"], "display": [{"locale": "en-US", "label": "Family Name"}]},
+    {"path": ["nationalities", null], "display": [{"locale": "en-US", "label": "Nationality"}]}
+  ],
+  "schema_uri": "https://credentials.example.com/identity_credential.schema.json"
+}`)
+
+var baseSchema = []byte(`{"type": "object", "required": ["given_name"]}`)
+
+func extendedMetadata(extendsIntegrity string) []byte {
+	b, _ := json.Marshal(map[string]any{
+		"vct":               extendedVct,
+		"name":              "Extended Identity Credential",
+		"extends":           baseVct,
+		"extends#integrity": extendsIntegrity,
+		"display": []any{
+			map[string]any{"locale": "en-US", "name": "Extended Identity Credential"},
+		},
+		"claims": []any{
+			map[string]any{"path": []any{"family_name"}, "display": []any{map[string]any{"locale": "en-US", "label": "Surname"}}},
+			map[string]any{"path": []any{"birthdate"}, "display": []any{map[string]any{"locale": "en-US", "label": "Date of Birth"}}},
+		},
+	})
+	return b
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	extended := extendedMetadata(sri(baseMetadata))
+	resolver := typemetadata.Resolver{Fetcher: typemetadata.MapFetcher{
+		baseVct:     baseMetadata,
+		extendedVct: extended,
+		"https://credentials.example.com/identity_credential.schema.json": baseSchema,
+	}}
+
+	metadata, err := resolver.Resolve(context.Background(), extendedVct, sri(extended))
+	require.NoError(t, err)
+
+	assert.Equal(t, extendedVct, metadata.Vct)
+	assert.Equal(t, "Extended Identity Credential", metadata.Name)
+	assert.Equal(t, "A basic identity credential", metadata.Description)
+	assert.Equal(t, []typemetadata.Display{
+		{Locale: "en-US", Name: "Extended Identity Credential"},
+		{Locale: "de-DE", Name: "Identitätsnachweis"},
+	}, metadata.Display)
+	assert.Equal(t, map[string]any{"type": "object", "required": []any{"given_name"}}, metadata.Schema)
+
+	require.Len(t, metadata.Claims, 4)
+	assert.Equal(t, go_sd_jwt.ClaimPath{"given_name"}, metadata.Claims[0].Path)
+	assert.Equal(t, "Given Name", metadata.Claims[0].Display[0].Label)
+	assert.Equal(t, go_sd_jwt.ClaimPath{"family_name"}, metadata.Claims[1].Path)
+	assert.Equal(t, "Surname", metadata.Claims[1].Display[0].Label)
+	assert.Equal(t, go_sd_jwt.ClaimPath{"nationalities", nil}, metadata.Claims[2].Path)
+	assert.Equal(t, go_sd_jwt.ClaimPath{"birthdate"}, metadata.Claims[3].Path)
+}
+
+func TestResolver_Resolve_InheritsSd(t *testing.T) {
+	resolver := typemetadata.Resolver{Fetcher: typemetadata.MapFetcher{
+		baseVct:     []byte(`{"vct":"` + baseVct + `","claims":[{"path":["given_name"],"sd":"never"}]}`),
+		extendedVct: []byte(`{"vct":"` + extendedVct + `","extends":"` + baseVct + `","claims":[{"path":["given_name"],"display":[{"locale":"en-US","label":"First Name"}]}]}`),
+	}}
+
+	metadata, err := resolver.Resolve(context.Background(), extendedVct, "")
+	require.NoError(t, err)
+	require.Len(t, metadata.Claims, 1)
+	assert.Equal(t, typemetadata.SdNever, metadata.Claims[0].SdRule())
+	assert.Equal(t, "First Name", metadata.Claims[0].Display[0].Label)
+}
+
+func TestResolver_ResolveSdJwt(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"vct":"https://` + r.Host + r.URL.Path + `","name":"Identity Credential"}`))
+	}))
+	defer server.Close()
+	vct := server.URL + "/identity_credential"
+
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	resolver := typemetadata.Resolver{Fetcher: typemetadata.HTTPFetcher{Client: server.Client()}}
+
+	document, err := resolver.Fetcher.Fetch(context.Background(), vct)
+	require.NoError(t, err)
+	sdJwt, err := sdjwtvc.Issue(map[string]any{"given_name": "Erika"}, sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+		Vct:             vct,
+		VctIntegrity:    sri(document),
+	})
+	require.NoError(t, err)
+
+	metadata, err := resolver.ResolveSdJwt(context.Background(), sdJwt)
+	require.NoError(t, err)
+	assert.Equal(t, "Identity Credential", metadata.Name)
+
+	sdJwt.Body["vct#integrity"] = sri([]byte("other"))
+	_, err = resolver.ResolveSdJwt(context.Background(), sdJwt)
+	require.Error(t, err)
+	assert.Equal(t, "type metadata for "+vct+" failed the integrity check: document does not match the sha256 integrity metadata", err.Error())
+}
+
+func TestResolver_Errors(t *testing.T) {
+	cycle := func(vct, extends string) []byte {
+		return []byte(`{"vct":"` + vct + `","extends":"` + extends + `"}`)
+	}
+
+	tests := []struct {
+		name      string
+		documents typemetadata.MapFetcher
+		vct       string
+		maxDepth  int
+		err       string
+	}{
+		{
+			name:      "not found",
+			documents: typemetadata.MapFetcher{},
+			vct:       baseVct,
+			err:       "failed to retrieve type metadata for " + baseVct + ": no document found for " + baseVct,
+		},
+		{
+			name: "extended type integrity mismatch",
+			documents: typemetadata.MapFetcher{
+				baseVct:     baseMetadata,
+				extendedVct: extendedMetadata(sri([]byte("other"))),
+			},
+			vct: extendedVct,
+			err: "type metadata for " + baseVct + " failed the integrity check: document does not match the sha256 integrity metadata",
+		},
+		{
+			name: "schema integrity mismatch",
+			documents: typemetadata.MapFetcher{
+				baseVct: []byte(`{"vct":"` + baseVct + `","schema_uri":"https://credentials.example.com/schema.json","schema_uri#integrity":"` + sri([]byte("other")) + `"}`),
+				"https://credentials.example.com/schema.json": baseSchema,
+			},
+			vct: baseVct,
+			err: "schema https://credentials.example.com/schema.json failed the integrity check: document does not match the sha256 integrity metadata",
+		},
+		{
+			name: "schema and schema_uri",
+			documents: typemetadata.MapFetcher{
+				baseVct: []byte(`{"vct":"` + baseVct + `","schema":{},"schema_uri":"https://credentials.example.com/schema.json"}`),
+			},
+			vct: baseVct,
+			err: "type metadata for " + baseVct + " must not contain both schema and schema_uri",
+		},
+		{
+			name: "invalid sd value",
+			documents: typemetadata.MapFetcher{
+				baseVct: []byte(`{"vct":"` + baseVct + `","claims":[{"path":["given_name"],"sd":"sometimes"}]}`),
+			},
+			vct: baseVct,
+			err: "type metadata for " + baseVct + " has an invalid sd value sometimes for claim given_name",
+		},
+		{
+			name: "extending type changes sd",
+			documents: typemetadata.MapFetcher{
+				baseVct:     []byte(`{"vct":"` + baseVct + `","claims":[{"path":["given_name"],"sd":"always"}]}`),
+				extendedVct: []byte(`{"vct":"` + extendedVct + `","extends":"` + baseVct + `","claims":[{"path":["given_name"],"sd":"allowed"}]}`),
+			},
+			vct: extendedVct,
+			err: "type metadata for " + extendedVct + " changes the sd value of claim given_name from always to allowed",
+		},
+		{
+			name:      "vct mismatch",
+			documents: typemetadata.MapFetcher{extendedVct: baseMetadata},
+			vct:       extendedVct,
+			err:       "type metadata retrieved for " + extendedVct + " has vct " + baseVct,
+		},
+		{
+			name:      "invalid document",
+			documents: typemetadata.MapFetcher{baseVct: []byte(`[]`)},
+			vct:       baseVct,
+			err:       "invalid type metadata for " + baseVct + ": json: cannot unmarshal array into Go value of type typemetadata.TypeMetadata",
+		},
+		{
+			name: "cycle",
+			documents: typemetadata.MapFetcher{
+				"https://example.com/a": cycle("https://example.com/a", "https://example.com/b"),
+				"https://example.com/b": cycle("https://example.com/b", "https://example.com/c"),
+				"https://example.com/c": cycle("https://example.com/c", "https://example.com/a"),
+			},
+			vct: "https://example.com/a",
+			err: "extends chain contains a cycle at https://example.com/a",
+		},
+		{
+			name: "self reference",
+			documents: typemetadata.MapFetcher{
+				"https://example.com/a": cycle("https://example.com/a", "https://example.com/a"),
+			},
+			vct: "https://example.com/a",
+			err: "extends chain contains a cycle at https://example.com/a",
+		},
+		{
+			name: "too deep",
+			documents: typemetadata.MapFetcher{
+				"https://example.com/a": cycle("https://example.com/a", "https://example.com/b"),
+				"https://example.com/b": cycle("https://example.com/b", "https://example.com/c"),
+				"https://example.com/c": []byte(`{"vct":"https://example.com/c"}`),
+			},
+			vct:      "https://example.com/a",
+			maxDepth: 1,
+			err:      "extends chain exceeds the maximum depth",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := typemetadata.Resolver{Fetcher: tt.documents, MaxDepth: tt.maxDepth}
+			_, err := resolver.Resolve(context.Background(), tt.vct, "")
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
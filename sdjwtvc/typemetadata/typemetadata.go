@@ -0,0 +1,97 @@
+// Package typemetadata resolves SD-JWT VC Type Metadata documents, following the extends chain of a type and checking
+// the integrity of each retrieved document against its subresource integrity metadata.
+package typemetadata
+
+import (
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+)
+
+// TypeMetadata is an SD-JWT VC Type Metadata document describing a vct
+type TypeMetadata struct {
+	Vct                string          `json:"vct"`
+	Name               string          `json:"name,omitempty"`
+	Description        string          `json:"description,omitempty"`
+	Extends            string          `json:"extends,omitempty"`
+	ExtendsIntegrity   string          `json:"extends#integrity,omitempty"`
+	Display            []Display       `json:"display,omitempty"`
+	Claims             []ClaimMetadata `json:"claims,omitempty"`
+	Schema             map[string]any  `json:"schema,omitempty"`
+	SchemaUri          string          `json:"schema_uri,omitempty"`
+	SchemaUriIntegrity string          `json:"schema_uri#integrity,omitempty"`
+}
+
+// Display is the display information for a type in one locale
+type Display struct {
+	Locale      string     `json:"locale"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Rendering   *Rendering `json:"rendering,omitempty"`
+}
+
+// Rendering describes how a credential of the type is rendered
+type Rendering struct {
+	Simple       *SimpleRendering `json:"simple,omitempty"`
+	SvgTemplates []SvgTemplate    `json:"svg_templates,omitempty"`
+}
+
+// SimpleRendering is the rendering method for applications which display a logo and colours only
+type SimpleRendering struct {
+	Logo            *Logo  `json:"logo,omitempty"`
+	BackgroundColor string `json:"background_color,omitempty"`
+	TextColor       string `json:"text_color,omitempty"`
+}
+
+// Logo references the logo of a type
+type Logo struct {
+	Uri          string `json:"uri"`
+	UriIntegrity string `json:"uri#integrity,omitempty"`
+	AltText      string `json:"alt_text,omitempty"`
+}
+
+// SvgTemplate references an SVG template in which the claims of a credential are substituted for {{svg_id}} placeholders
+type SvgTemplate struct {
+	Uri          string                 `json:"uri"`
+	UriIntegrity string                 `json:"uri#integrity,omitempty"`
+	Properties   *SvgTemplateProperties `json:"properties,omitempty"`
+}
+
+// SvgTemplateProperties distinguish between the SVG templates of a type
+type SvgTemplateProperties struct {
+	Orientation string `json:"orientation,omitempty"`
+	ColorScheme string `json:"color_scheme,omitempty"`
+	Contrast    string `json:"contrast,omitempty"`
+}
+
+// The values of the sd property of claim metadata
+const (
+	// SdAlways requires the claim to be selectively disclosable
+	SdAlways = "always"
+	// SdAllowed leaves it to the issuer whether the claim is selectively disclosable
+	SdAllowed = "allowed"
+	// SdNever requires the claim to be included in plaintext
+	SdNever = "never"
+)
+
+// ClaimMetadata describes the claim, or claims, selected by Path
+type ClaimMetadata struct {
+	Path    go_sd_jwt.ClaimPath `json:"path"`
+	Display []ClaimDisplay      `json:"display,omitempty"`
+	// Sd is one of SdAlways, SdAllowed or SdNever, defaults to SdAllowed
+	Sd    string `json:"sd,omitempty"`
+	SvgId string `json:"svg_id,omitempty"`
+}
+
+// SdRule returns the sd property of the claim metadata, SdAllowed if it is not set
+func (c ClaimMetadata) SdRule() string {
+	if c.Sd == "" {
+		return SdAllowed
+	}
+	return c.Sd
+}
+
+// ClaimDisplay is the display information for a claim in one locale
+type ClaimDisplay struct {
+	Locale      string `json:"locale"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+}
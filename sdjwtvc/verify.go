@@ -0,0 +1,147 @@
+package sdjwtvc
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/utils"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc/typemetadata"
+)
+
+// VerificationOptions configures how Verify checks an SD-JWT VC.
+// The embedded VerificationOptions are passed to SdJwt.Verify.
+type VerificationOptions struct {
+	go_sd_jwt.VerificationOptions
+	// ExpectedVct, when set, must match the vct claim
+	ExpectedVct string
+	// ExpectedIssuer, when set, must match the iss claim
+	ExpectedIssuer string
+	// TypeMetadata is the resolved type metadata for the vct. When set, the vct must match and the credential must
+	// follow its sd rules
+	TypeMetadata *typemetadata.TypeMetadata
+	// ValidateSchema, when set, validates the disclosed claims against the schema of TypeMetadata with
+	// ValidateDisclosedClaims
+	ValidateSchema bool
+}
+
+// Credential is a verified SD-JWT VC. The registered claims are split out into typed fields, Claims contains all other
+// claims which were disclosed or included in plaintext.
+type Credential struct {
+	Vct          string
+	VctIntegrity *string
+	Iss          string
+	Sub          *string
+	Iat          *time.Time
+	Nbf          *time.Time
+	Exp          *time.Time
+	Cnf          *go_sd_jwt.Confirmation
+	Status       *Status
+	Claims       map[string]any
+}
+
+var registeredClaims = []string{"iss", "sub", "iat", "nbf", "exp", "cnf", "vct", "vct#integrity", "status"}
+
+// Verify verifies an SD-JWT VC with SdJwt.Verify and returns its disclosed claims.
+// In addition to the checks configured through opts, the typ header must be dc+sd-jwt (or the legacy vc+sd-jwt), the
+// vct and iss claims must be present, none of the claims listed in NonSelectivelyDisclosableClaims may be selectively
+// disclosed, and the registered claims including the structure of the cnf and status claims must be valid. When
+// opts.TypeMetadata is set, claims it requires to never be selectively disclosable must not be disclosed through a
+// disclosure and claims it requires to always be selectively disclosable must not be included in plaintext.
+func Verify(sdJwt *go_sd_jwt.SdJwt, opts VerificationOptions) (*Credential, error) {
+	if typ := sdJwt.Typ(); typ == nil || !validTyp(*typ) {
+		return nil, fmt.Errorf("%wtyp header must be %s or %s", e.ErrInvalidToken, Typ, LegacyTyp)
+	}
+
+	if err := sdJwt.Verify(opts.VerificationOptions); err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{"vct", "iss"} {
+		if _, ok := sdJwt.Body[name]; !ok {
+			return nil, fmt.Errorf("%w%s claim must be included in plaintext", e.ErrInvalidToken, name)
+		}
+	}
+	if err := validateRegisteredClaims(sdJwt.Body); err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidToken, err.Error())
+	}
+
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	if err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidToken, err.Error())
+	}
+	for _, name := range NonSelectivelyDisclosableClaims {
+		if _, ok := sdJwt.Body[name]; !ok {
+			if _, ok := disclosed[name]; ok {
+				return nil, fmt.Errorf("%wclaim %s must not be selectively disclosable in an SD-JWT VC", e.ErrInvalidToken, name)
+			}
+		}
+	}
+	// sub and iat may be selectively disclosed, so their types are checked again against the disclosed values
+	if err := validateRegisteredClaims(disclosed); err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidToken, err.Error())
+	}
+
+	cnf, err := sdJwt.Confirmation()
+	if err != nil {
+		return nil, fmt.Errorf("%winvalid cnf claim: %s", e.ErrInvalidToken, err.Error())
+	}
+
+	credential := &Credential{
+		Vct:    disclosed["vct"].(string),
+		Cnf:    cnf,
+		Claims: make(map[string]any, len(disclosed)),
+	}
+	credential.Iss, _ = disclosed["iss"].(string)
+	if v, ok := disclosed["vct#integrity"].(string); ok {
+		credential.VctIntegrity = &v
+	}
+	if v, ok := disclosed["sub"].(string); ok {
+		credential.Sub = &v
+	}
+	credential.Iat = dateClaim(disclosed, "iat")
+	credential.Nbf = dateClaim(disclosed, "nbf")
+	credential.Exp = dateClaim(disclosed, "exp")
+	if v, ok := disclosed["status"]; ok {
+		credential.Status, _ = parseStatus(v)
+	}
+
+	if opts.ExpectedVct != "" && credential.Vct != opts.ExpectedVct {
+		return nil, fmt.Errorf("%wvct mismatch: expected %s", e.ErrInvalidToken, opts.ExpectedVct)
+	}
+	if opts.ExpectedIssuer != "" && credential.Iss != opts.ExpectedIssuer {
+		return nil, fmt.Errorf("%wiss mismatch: expected %s", e.ErrInvalidToken, opts.ExpectedIssuer)
+	}
+
+	if opts.TypeMetadata != nil {
+		if opts.TypeMetadata.Vct != credential.Vct {
+			return nil, fmt.Errorf("%wtype metadata is for vct %s", e.ErrInvalidToken, opts.TypeMetadata.Vct)
+		}
+		if err := checkSdRules(sdJwt, opts.TypeMetadata); err != nil {
+			return nil, fmt.Errorf("%w%s", e.ErrInvalidToken, err.Error())
+		}
+		if opts.ValidateSchema && opts.TypeMetadata.Schema != nil {
+			if err := ValidateDisclosedClaims(sdJwt, opts.TypeMetadata.Schema); err != nil {
+				return nil, fmt.Errorf("%w%w", e.ErrInvalidToken, err)
+			}
+		}
+	}
+
+	for k, v := range disclosed {
+		if !slices.Contains(registeredClaims, k) {
+			credential.Claims[k] = v
+		}
+	}
+	return credential, nil
+}
+
+func dateClaim(claims map[string]any, name string) *time.Time {
+	n, ok := utils.NumericDate(claims[name])
+	if !ok {
+		return nil
+	}
+	t := utils.NumericTime(n).UTC()
+	return &t
+}
@@ -0,0 +1,206 @@
+package sdjwtvc_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/sdjwtvc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cnf, err := go_sd_jwt.NewJwkConfirmation(&holderKey.PublicKey)
+	require.NoError(t, err)
+
+	claims := credentialClaims()
+	claims["sub"] = "user_42"
+	issued, err := sdjwtvc.Issue(claims, sdjwtvc.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			Cnf:                    cnf,
+			SelectivelyDisclosable: []string{"sub", "given_name", "family_name", "address.street_address"},
+		},
+		Vct:          "https://credentials.example.com/identity_credential",
+		VctIntegrity: "sha256-0000000000000000000000000000000000000000000=",
+		Iss:          "https://issuer.example.com",
+		Status: &sdjwtvc.Status{
+			StatusList: &sdjwtvc.StatusListReference{Idx: 412, Uri: "https://issuer.example.com/statuslists/1"},
+		},
+	})
+	require.NoError(t, err)
+
+	disclosures, err := issued.SelectDisclosures("sub", "given_name")
+	require.NoError(t, err)
+	issued.Disclosures = disclosures
+	token, err := issued.Token()
+	require.NoError(t, err)
+	sdJwt, err := go_sd_jwt.New(*token)
+	require.NoError(t, err)
+
+	credential, err := sdjwtvc.Verify(sdJwt, sdjwtvc.VerificationOptions{
+		VerificationOptions: go_sd_jwt.VerificationOptions{
+			IssuerKey:      issuerSigner.Public(),
+			ValidateExpiry: true,
+		},
+		ExpectedVct:    "https://credentials.example.com/identity_credential",
+		ExpectedIssuer: "https://issuer.example.com",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://credentials.example.com/identity_credential", credential.Vct)
+	assert.Equal(t, "sha256-0000000000000000000000000000000000000000000=", *credential.VctIntegrity)
+	assert.Equal(t, "https://issuer.example.com", credential.Iss)
+	assert.Equal(t, "user_42", *credential.Sub)
+	assert.Equal(t, time.Unix(1683000000, 0).UTC(), *credential.Iat)
+	assert.Equal(t, time.Unix(1883000000, 0).UTC(), *credential.Exp)
+	assert.Nil(t, credential.Nbf)
+	assert.Equal(t, cnf.Jwk, credential.Cnf.Jwk)
+	assert.Equal(t, &sdjwtvc.StatusListReference{Idx: 412, Uri: "https://issuer.example.com/statuslists/1"}, credential.Status.StatusList)
+	assert.Equal(t, map[string]any{
+		"given_name": "Erika",
+		"address":    map[string]any{"locality": "Köln"},
+	}, credential.Claims)
+}
+
+func TestVerify_Errors(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	vcClaims := func(modify func(claims map[string]any)) map[string]any {
+		claims := credentialClaims()
+		claims["vct"] = "https://credentials.example.com/identity_credential"
+		claims["iss"] = "https://issuer.example.com"
+		if modify != nil {
+			modify(claims)
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name   string
+		claims map[string]any
+		opts   go_sd_jwt.IssuanceOptions
+		vcOpts sdjwtvc.VerificationOptions
+		err    string
+	}{
+		{
+			name:   "missing typ",
+			claims: vcClaims(nil),
+			err:    "invalid token: typ header must be dc+sd-jwt or vc+sd-jwt",
+		},
+		{
+			name:   "other typ",
+			claims: vcClaims(nil),
+			opts:   go_sd_jwt.IssuanceOptions{Typ: "example+sd-jwt"},
+			err:    "invalid token: typ header must be dc+sd-jwt or vc+sd-jwt",
+		},
+		{
+			name:   "vct selectively disclosed",
+			claims: vcClaims(nil),
+			opts:   go_sd_jwt.IssuanceOptions{Typ: sdjwtvc.Typ, SelectivelyDisclosable: []string{"vct"}},
+			err:    "invalid token: vct claim must be included in plaintext",
+		},
+		{
+			name:   "iss selectively disclosed",
+			claims: vcClaims(nil),
+			opts:   go_sd_jwt.IssuanceOptions{Typ: sdjwtvc.Typ, SelectivelyDisclosable: []string{"iss"}},
+			err:    "invalid token: iss claim must be included in plaintext",
+		},
+		{
+			name:   "exp selectively disclosed",
+			claims: vcClaims(nil),
+			opts:   go_sd_jwt.IssuanceOptions{Typ: sdjwtvc.Typ, SelectivelyDisclosable: []string{"exp"}},
+			err:    "invalid token: claim exp must not be selectively disclosable in an SD-JWT VC",
+		},
+		{
+			name: "missing iss",
+			claims: vcClaims(func(claims map[string]any) {
+				delete(claims, "iss")
+			}),
+			opts: go_sd_jwt.IssuanceOptions{Typ: sdjwtvc.Typ},
+			err:  "invalid token: iss claim must be included in plaintext",
+		},
+		{
+			name: "sub not a string",
+			claims: vcClaims(func(claims map[string]any) {
+				claims["sub"] = 42
+			}),
+			opts: go_sd_jwt.IssuanceOptions{Typ: sdjwtvc.Typ, SelectivelyDisclosable: []string{"sub"}},
+			err:  "invalid token: sub claim must be a string",
+		},
+		{
+			name: "status_list without uri",
+			claims: vcClaims(func(claims map[string]any) {
+				claims["status"] = map[string]any{"status_list": map[string]any{"idx": 1}}
+			}),
+			opts: go_sd_jwt.IssuanceOptions{Typ: sdjwtvc.Typ},
+			err:  "invalid token: status_list uri must be a non-empty string",
+		},
+		{
+			name: "status_list with fractional idx",
+			claims: vcClaims(func(claims map[string]any) {
+				claims["status"] = map[string]any{"status_list": map[string]any{"idx": 1.5, "uri": "https://issuer.example.com/statuslists/1"}}
+			}),
+			opts: go_sd_jwt.IssuanceOptions{Typ: sdjwtvc.Typ},
+			err:  "invalid token: status_list idx must be a non-negative integer",
+		},
+		{
+			name: "cnf without a confirmation method",
+			claims: vcClaims(func(claims map[string]any) {
+				claims["cnf"] = map[string]any{"x5t#S256": "thumbprint"}
+			}),
+			opts: go_sd_jwt.IssuanceOptions{Typ: sdjwtvc.Typ},
+			err:  "invalid token: invalid cnf claim: cnf must contain at least one of jwk, kid or jkt",
+		},
+		{
+			name:   "unexpected vct",
+			claims: vcClaims(nil),
+			opts:   go_sd_jwt.IssuanceOptions{Typ: sdjwtvc.Typ},
+			vcOpts: sdjwtvc.VerificationOptions{ExpectedVct: "https://credentials.example.com/other"},
+			err:    "invalid token: vct mismatch: expected https://credentials.example.com/other",
+		},
+		{
+			name:   "unexpected issuer",
+			claims: vcClaims(nil),
+			opts:   go_sd_jwt.IssuanceOptions{Typ: sdjwtvc.Typ},
+			vcOpts: sdjwtvc.VerificationOptions{ExpectedIssuer: "https://other.example.com"},
+			err:    "invalid token: iss mismatch: expected https://other.example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Signer = issuerSigner
+			sdJwt, err := go_sd_jwt.Issue(tt.claims, tt.opts)
+			require.NoError(t, err)
+
+			tt.vcOpts.IssuerKey = issuerSigner.Public()
+			_, err = sdjwtvc.Verify(sdJwt, tt.vcOpts)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+
+	t.Run("invalid signature", func(t *testing.T) {
+		sdJwt, err := sdjwtvc.Issue(vcClaims(nil), sdjwtvc.IssuanceOptions{IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner}})
+		require.NoError(t, err)
+		otherSigner, err := jws.GetSigner(model.ES256, nil)
+		require.NoError(t, err)
+
+		_, err = sdjwtvc.Verify(sdJwt, sdjwtvc.VerificationOptions{
+			VerificationOptions: go_sd_jwt.VerificationOptions{IssuerKey: otherSigner.Public()},
+		})
+		require.Error(t, err)
+		assert.Equal(t, "invalid token: signature verification failed", err.Error())
+	})
+}
@@ -0,0 +1,170 @@
+package go_sd_jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	josemodel "github.com/MichaelFraser99/go-jose/model"
+)
+
+// algToHash returns the hash function used by the provided JWS algorithm.
+// Only the asymmetric algorithms supported by Verify are accepted.
+func algToHash(alg string) (crypto.Hash, error) {
+	switch alg {
+	case "ES256", "RS256", "PS256":
+		return crypto.SHA256, nil
+	case "ES384", "RS384", "PS384":
+		return crypto.SHA384, nil
+	case "ES512", "RS512", "PS512":
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported signing algorithm: %s", alg)
+	}
+}
+
+// ecKeySize returns the size in bytes of a JWS encoded (r || s) signature for the provided ES algorithm
+func ecKeySize(alg string) int {
+	switch alg {
+	case "ES256":
+		return 64
+	case "ES384":
+		return 96
+	case "ES512":
+		return 132
+	default:
+		return 0
+	}
+}
+
+// resolveAlg returns the JWS algorithm to sign with. If no algorithm is provided, it is taken from the signer when
+// the signer is a go-jose signer.
+func resolveAlg(signer crypto.Signer, alg string) (string, error) {
+	if alg == "" {
+		s, ok := signer.(josemodel.Signer)
+		if !ok {
+			return "", errors.New("an algorithm must be specified when the signer does not declare one")
+		}
+		return s.Alg().String(), nil
+	}
+	return strings.ToUpper(alg), nil
+}
+
+// sign produces a JWS encoded signature over the provided signing input
+func sign(signer crypto.Signer, alg string, signingInput string) ([]byte, error) {
+	h, err := algToHash(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := h.New()
+	hasher.Write([]byte(signingInput))
+	digest := hasher.Sum(nil)
+
+	var opts crypto.SignerOpts = h
+	if strings.HasPrefix(alg, "PS") {
+		opts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: h}
+	}
+
+	sig, err := signer.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// go-jose signers already produce JWS encoded signatures, standard library ecdsa signers produce ASN.1 DER
+	if _, ok := signer.(josemodel.Signer); !ok && strings.HasPrefix(alg, "ES") {
+		return derToJWS(sig, ecKeySize(alg))
+	}
+
+	return sig, nil
+}
+
+// derToJWS converts an ASN.1 DER encoded ecdsa signature to the fixed length r || s encoding required by JWS
+func derToJWS(der []byte, keySize int) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil || len(rest) > 0 {
+		return nil, errors.New("ecdsa signature is not valid ASN.1 DER")
+	}
+	if sig.R.BitLen() > keySize*4 || sig.S.BitLen() > keySize*4 {
+		return nil, errors.New("ecdsa signature is too large for the specified algorithm")
+	}
+
+	out := make([]byte, keySize)
+	sig.R.FillBytes(out[:keySize/2])
+	sig.S.FillBytes(out[keySize/2:])
+	return out, nil
+}
+
+// checkKeyMatchesAlg returns an error if the provided public key cannot be used with the provided algorithm
+func checkKeyMatchesAlg(publicKey crypto.PublicKey, alg string) error {
+	switch k := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		expected := map[string]string{"ES256": "P-256", "ES384": "P-384", "ES512": "P-521"}[alg]
+		if expected == "" || k.Curve.Params().Name != expected {
+			return fmt.Errorf("algorithm %s cannot be used with an ecdsa %s key", alg, k.Curve.Params().Name)
+		}
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") && !strings.HasPrefix(alg, "PS") {
+			return fmt.Errorf("algorithm %s cannot be used with an rsa key", alg)
+		}
+	default:
+		return fmt.Errorf("unsupported signing key type: %T", publicKey)
+	}
+	return nil
+}
+
+// checkSignature checks that the signature uses the JWS encoding for the algorithm and, when the public key is known,
+// that it is valid for the signing input
+func checkSignature(publicKey crypto.PublicKey, alg string, signingInput string, signature []byte) error {
+	h, err := algToHash(alg)
+	if err != nil {
+		return err
+	}
+	if len(signature) == 0 {
+		return errors.New("signature must not be empty")
+	}
+
+	if strings.HasPrefix(alg, "ES") {
+		if keySize := ecKeySize(alg); len(signature) != keySize {
+			return fmt.Errorf("%s signatures must be the %d byte r || s encoding, got %d bytes", alg, keySize, len(signature))
+		}
+	}
+	if publicKey == nil {
+		return nil
+	}
+
+	hasher := h.New()
+	hasher.Write([]byte(signingInput))
+	digest := hasher.Sum(nil)
+
+	valid := false
+	switch k := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		half := len(signature) / 2
+		valid = ecdsa.Verify(k, digest, new(big.Int).SetBytes(signature[:half]), new(big.Int).SetBytes(signature[half:]))
+	case *rsa.PublicKey:
+		if len(signature) != k.Size() {
+			return fmt.Errorf("%s signatures must be %d bytes for the issuer key, got %d bytes", alg, k.Size(), len(signature))
+		}
+		if strings.HasPrefix(alg, "PS") {
+			valid = rsa.VerifyPSS(k, h, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		} else {
+			valid = rsa.VerifyPKCS1v15(k, h, digest, signature) == nil
+		}
+	default:
+		return fmt.Errorf("unsupported signing key type: %T", publicKey)
+	}
+	if !valid {
+		return errors.New("signature is not valid for the signing input")
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package go_sd_jwt
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// IssueFromStruct creates a new signed SD-JWT from a tagged Go struct.
+// The claims are the JSON encoding of v and the selectively disclosable claims are taken from the sdjwt struct tags
+// (see StructDisclosurePaths), in addition to any paths already listed in opts.SelectivelyDisclosable.
+func IssueFromStruct(v any, opts IssuanceOptions) (*SdJwt, error) {
+	paths, err := StructDisclosurePaths(v)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("%wfailed to marshal claims: %s", e.ErrInvalidIssuance, err.Error())
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var claims map[string]any
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, fmt.Errorf("%wclaims must marshal to a JSON object: %s", e.ErrInvalidIssuance, err.Error())
+	}
+
+	opts.SelectivelyDisclosable = append(append([]string{}, opts.SelectivelyDisclosable...), paths...)
+	return Issue(claims, opts)
+}
+
+// StructDisclosurePaths returns the claim paths of the selectively disclosable claims declared on a Go struct using the
+// sdjwt struct tag. Claim names are taken from the json struct tag in the same way as encoding/json. Supported options:
+//   - sd: the claim itself is selectively disclosable
+//   - elements: every element of the slice or array claim is selectively disclosable
+//
+// Options may be combined, e.g. `json:"nationalities" sdjwt:"sd,elements"`. Nested structs, including structs held in
+// slices, arrays and pointers, are processed recursively. Fields omitted from the JSON encoding are skipped.
+func StructDisclosurePaths(v any) ([]string, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("%wclaims must not be nil", e.ErrInvalidIssuance)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%wclaims must be a struct, got %s", e.ErrInvalidIssuance, rv.Kind())
+	}
+
+	var paths []string
+	if err := structPaths(rv, nil, &paths); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+func structPaths(rv reflect.Value, path ClaimPath, paths *[]string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+
+		name, omitEmpty, omitZero, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := fv
+			for embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					break
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Pointer && embedded.Type().Elem().Kind() == reflect.Struct {
+				// nil embedded struct pointers are omitted from the JSON encoding
+				continue
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := structPaths(embedded, path, paths); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if (omitEmpty && isEmptyValue(fv)) || (omitZero && fv.IsZero()) {
+			continue
+		}
+
+		sd, elements, err := parseSdJwtTag(field)
+		if err != nil {
+			return err
+		}
+
+		claimPath := path.Append(name)
+		if elements {
+			kind := indirect(fv).Kind()
+			if kind != reflect.Slice && kind != reflect.Array {
+				return fmt.Errorf("%wfield %s has the elements option but is not a slice or array", e.ErrInvalidIssuance, field.Name)
+			}
+		}
+
+		if err := valuePaths(fv, claimPath, paths, elements); err != nil {
+			return err
+		}
+		if sd {
+			*paths = append(*paths, claimPath.String())
+		}
+	}
+	return nil
+}
+
+// valuePaths collects the paths declared within the provided value, nested paths are added before the path of their
+// parent element
+func valuePaths(v reflect.Value, path ClaimPath, paths *[]string, elements bool) error {
+	v = indirect(v)
+	if !v.IsValid() || hasCustomEncoding(v.Type()) {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return structPaths(v, path, paths)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := valuePaths(v.Index(i), path.Append(i), paths, false); err != nil {
+				return err
+			}
+		}
+		if elements {
+			*paths = append(*paths, path.Append(nil).String())
+		}
+	}
+	return nil
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func hasCustomEncoding(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PointerTo(t).Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+// jsonFieldName returns the JSON name of the field, whether it is omitted when empty or zero and whether it is skipped
+// entirely
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty, omitZero, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+		return "", false, false, true
+	}
+	parts := strings.Split(tag, ",")
+	for _, option := range parts[1:] {
+		switch option {
+		case "omitempty":
+			omitEmpty = true
+		case "omitzero":
+			omitZero = true
+		}
+	}
+	return parts[0], omitEmpty, omitZero, false
+}
+
+// isEmptyValue matches the omitempty behaviour of encoding/json
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
+
+func parseSdJwtTag(field reflect.StructField) (sd bool, elements bool, err error) {
+	tag, ok := field.Tag.Lookup("sdjwt")
+	if !ok || tag == "" {
+		return false, false, nil
+	}
+	for _, option := range strings.Split(tag, ",") {
+		switch strings.TrimSpace(option) {
+		case "sd":
+			sd = true
+		case "elements":
+			elements = true
+		default:
+			return false, false, fmt.Errorf("%wunknown sdjwt tag option %q on field %s", e.ErrInvalidIssuance, option, field.Name)
+		}
+	}
+	return sd, elements, nil
+}
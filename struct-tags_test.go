@@ -0,0 +1,211 @@
+package go_sd_jwt_test
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type structAddress struct {
+	StreetAddress string `json:"street_address" sdjwt:"sd"`
+	Locality      string `json:"locality"`
+	Country       string `json:"country" sdjwt:"sd"`
+}
+
+type structDocument struct {
+	Type   string `json:"type"`
+	Number string `json:"number" sdjwt:"sd"`
+}
+
+type structBase struct {
+	Iss string `json:"iss"`
+	Sub string `json:"sub" sdjwt:"sd"`
+}
+
+type structClaims struct {
+	structBase
+	GivenName     string           `json:"given_name" sdjwt:"sd"`
+	FamilyName    string           `json:"family_name,omitempty" sdjwt:"sd"`
+	MiddleName    string           `json:"middle_name,omitempty" sdjwt:"sd"`
+	Address       *structAddress   `json:"address" sdjwt:"sd"`
+	Nationalities []string         `json:"nationalities" sdjwt:"sd,elements"`
+	Documents     []structDocument `json:"documents"`
+	IssuedAt      time.Time        `json:"issued_at" sdjwt:"sd"`
+	internal      string
+	Ignored       string `json:"-" sdjwt:"sd"`
+}
+
+func newStructClaims() structClaims {
+	return structClaims{
+		structBase: structBase{Iss: "https://issuer.example.com", Sub: "user_42"},
+		GivenName:  "John",
+		FamilyName: "Doe",
+		Address: &structAddress{
+			StreetAddress: "123 Main St",
+			Locality:      "Anytown",
+			Country:       "US",
+		},
+		Nationalities: []string{"US", "DE"},
+		Documents: []structDocument{
+			{Type: "passport", Number: "123"},
+			{Type: "idcard", Number: "456"},
+		},
+		IssuedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		internal: "internal",
+		Ignored:  "ignored",
+	}
+}
+
+func TestStructDisclosurePaths(t *testing.T) {
+	claims := newStructClaims()
+	paths, err := go_sd_jwt.StructDisclosurePaths(&claims)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"sub",
+		"given_name",
+		"family_name",
+		"address.street_address",
+		"address.country",
+		"address",
+		"nationalities[*]",
+		"nationalities",
+		"documents[0].number",
+		"documents[1].number",
+		"issued_at",
+	}, paths)
+}
+
+func TestIssueFromStruct(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := newStructClaims()
+	sdJwt, err := go_sd_jwt.IssueFromStruct(claims, go_sd_jwt.IssuanceOptions{Signer: signer})
+	require.NoError(t, err)
+	require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: signer.Public()}))
+
+	assert.Equal(t, "https://issuer.example.com", sdJwt.Body["iss"])
+	for _, claim := range []string{"sub", "given_name", "family_name", "middle_name", "address", "nationalities", "issued_at"} {
+		assert.NotContains(t, sdJwt.Body, claim)
+	}
+	assert.Len(t, sdJwt.Disclosures, 12)
+
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	require.NoError(t, err)
+	assert.Equal(t, roundTrip(t, claims), disclosed)
+
+	expected, err := go_sd_jwt.Issue(roundTrip(t, claims), go_sd_jwt.IssuanceOptions{
+		Signer: signer,
+		SelectivelyDisclosable: []string{
+			"sub", "given_name", "family_name", "address.street_address", "address.country", "address",
+			"nationalities[*]", "nationalities", "documents[*].number", "issued_at",
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, sdJwt.Disclosures, len(expected.Disclosures))
+	assert.Len(t, sdJwt.Body["_sd"], len(expected.Body["_sd"].([]any)))
+}
+
+func TestIssueFromStruct_AdditionalPaths(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := newStructClaims()
+	opts := go_sd_jwt.IssuanceOptions{Signer: signer, SelectivelyDisclosable: []string{"address.locality"}}
+	sdJwt, err := go_sd_jwt.IssueFromStruct(claims, opts)
+	require.NoError(t, err)
+	assert.Len(t, sdJwt.Disclosures, 13)
+	assert.Equal(t, []string{"address.locality"}, opts.SelectivelyDisclosable, "the provided options should not be modified")
+}
+
+func TestIssueFromStruct_Errors(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		claims any
+		err    string
+	}{
+		{
+			name:   "not a struct",
+			claims: map[string]any{"sub": "user_42"},
+			err:    "invalid issuance: claims must be a struct, got map",
+		},
+		{
+			name:   "nil pointer",
+			claims: (*structClaims)(nil),
+			err:    "invalid issuance: claims must not be nil",
+		},
+		{
+			name: "unknown option",
+			claims: struct {
+				Sub string `json:"sub" sdjwt:"sd,always"`
+			}{Sub: "user_42"},
+			err: `invalid issuance: unknown sdjwt tag option "always" on field Sub`,
+		},
+		{
+			name: "elements on a non array",
+			claims: struct {
+				Sub string `json:"sub" sdjwt:"elements"`
+			}{Sub: "user_42"},
+			err: "invalid issuance: field Sub has the elements option but is not a slice or array",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := go_sd_jwt.IssueFromStruct(tt.claims, go_sd_jwt.IssuanceOptions{Signer: signer})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+type StructExtra struct {
+	Extra string `json:"extra" sdjwt:"sd"`
+}
+
+type structWithEmbeddedPointer struct {
+	*StructExtra `sdjwt:"sd"`
+	Sub          string `json:"sub" sdjwt:"sd"`
+}
+
+func TestStructDisclosurePaths_NilEmbeddedPointer(t *testing.T) {
+	paths, err := go_sd_jwt.StructDisclosurePaths(structWithEmbeddedPointer{Sub: "user_42"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sub"}, paths)
+
+	paths, err = go_sd_jwt.StructDisclosurePaths(structWithEmbeddedPointer{StructExtra: &StructExtra{Extra: "value"}, Sub: "user_42"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"extra", "sub"}, paths)
+
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+	sdJwt, err := go_sd_jwt.IssueFromStruct(structWithEmbeddedPointer{Sub: "user_42"}, go_sd_jwt.IssuanceOptions{Signer: signer})
+	require.NoError(t, err)
+	assert.Len(t, sdJwt.Disclosures, 1)
+}
+
+func TestIssueFromStruct_LargeIntegers(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	claims := struct {
+		ID int64 `json:"id" sdjwt:"sd"`
+	}{ID: 9007199254740993}
+
+	sdJwt, err := go_sd_jwt.IssueFromStruct(claims, go_sd_jwt.IssuanceOptions{Signer: signer})
+	require.NoError(t, err)
+	require.Len(t, sdJwt.Disclosures, 1)
+
+	decoded, err := base64.RawURLEncoding.DecodeString(sdJwt.Disclosures[0].EncodedValue)
+	require.NoError(t, err)
+	assert.Contains(t, string(decoded), "9007199254740993")
+}
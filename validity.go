@@ -0,0 +1,127 @@
+package go_sd_jwt
+
+import (
+	"fmt"
+	"time"
+
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/utils"
+)
+
+// Validity configures the validity window of an issued SD-JWT through its iat, nbf and exp claims.
+// When any field is set, an iat claim set to Now is added to claims which do not contain one. The zero value leaves the
+// claims as provided.
+type Validity struct {
+	// Clock returns the current time, defaults to time.Now
+	Clock func() time.Time
+	// Lifetime, when set, adds an exp claim Lifetime after iat to claims which do not contain one
+	Lifetime time.Duration
+	// MaxLifetime, when set, is the longest validity window the issuer accepts. Claims without an exp claim are rejected
+	// unless Lifetime is set, as are claims whose exp is more than MaxLifetime after iat.
+	MaxLifetime time.Duration
+	// NotBefore adds an nbf claim equal to iat to claims which do not contain one
+	NotBefore bool
+	// Rounding, when set, rounds the added iat, nbf and exp claims down to a multiple of Rounding so that the time of
+	// issuance cannot be used to correlate presentations. It must be a whole number of seconds no longer than Lifetime.
+	Rounding time.Duration
+}
+
+// Now returns the current time from Clock, rounded down to a multiple of Rounding
+func (v Validity) Now() time.Time {
+	now := time.Now
+	if v.Clock != nil {
+		now = v.Clock
+	}
+	return v.round(now())
+}
+
+func (v Validity) round(t time.Time) time.Time {
+	seconds := int64(v.Rounding / time.Second)
+	if seconds <= 1 {
+		return time.Unix(t.Unix(), 0)
+	}
+	return time.Unix(t.Unix()-t.Unix()%seconds, 0)
+}
+
+func (v Validity) enabled() bool {
+	return v.Clock != nil || v.Lifetime != 0 || v.MaxLifetime != 0 || v.NotBefore || v.Rounding != 0
+}
+
+func (v Validity) validate() error {
+	if v.Lifetime < 0 || v.MaxLifetime < 0 || v.Rounding < 0 {
+		return fmt.Errorf("%wvalidity durations must not be negative", e.ErrInvalidIssuance)
+	}
+	if v.MaxLifetime != 0 && v.Lifetime > v.MaxLifetime {
+		return fmt.Errorf("%wlifetime must not be longer than the maximum lifetime", e.ErrInvalidIssuance)
+	}
+	if v.Rounding%time.Second != 0 {
+		return fmt.Errorf("%wrounding must be a whole number of seconds", e.ErrInvalidIssuance)
+	}
+	if v.Lifetime != 0 && v.Lifetime < v.Rounding {
+		return fmt.Errorf("%wlifetime must not be shorter than the rounding", e.ErrInvalidIssuance)
+	}
+	return nil
+}
+
+// apply adds the iat, nbf and exp claims to the body and checks the resulting validity window
+func (v Validity) apply(body map[string]any) error {
+	if !v.enabled() {
+		return nil
+	}
+	if err := v.validate(); err != nil {
+		return err
+	}
+
+	iat, ok, err := dateClaim(body, "iat")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		now := v.Now().Unix()
+		iat = float64(now)
+		body["iat"] = now
+	}
+
+	if _, ok, err := dateClaim(body, "nbf"); err != nil {
+		return err
+	} else if !ok && v.NotBefore {
+		body["nbf"] = body["iat"]
+	}
+
+	exp, ok, err := dateClaim(body, "exp")
+	if err != nil {
+		return err
+	}
+	if !ok && v.Lifetime != 0 {
+		added := v.round(utils.NumericTime(iat).Add(v.Lifetime)).Unix()
+		exp, ok = float64(added), true
+		body["exp"] = added
+	}
+
+	if !ok {
+		if v.MaxLifetime != 0 {
+			return fmt.Errorf("%wexp claim is required when a maximum lifetime is set", e.ErrInvalidIssuance)
+		}
+		return nil
+	}
+	if exp <= iat {
+		return fmt.Errorf("%wexp claim must be after iat", e.ErrInvalidIssuance)
+	}
+	if v.MaxLifetime != 0 && utils.NumericTime(exp).Sub(utils.NumericTime(iat)) > v.MaxLifetime {
+		return fmt.Errorf("%wexp claim must not be more than %s after iat", e.ErrInvalidIssuance, v.MaxLifetime)
+	}
+	return nil
+}
+
+// dateClaim returns the value of a numeric date claim, which may be fractional, and whether the claim is present
+func dateClaim(body map[string]any, name string) (float64, bool, error) {
+	v, ok := body[name]
+	if !ok {
+		return 0, false, nil
+	}
+	n, ok := utils.NumericDate(v)
+	if !ok {
+		return 0, false, fmt.Errorf("%w%s claim must be a numeric date", e.ErrInvalidIssuance, name)
+	}
+	return n, true, nil
+}
@@ -0,0 +1,215 @@
+package go_sd_jwt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixedClock(unix int64) func() time.Time {
+	return func() time.Time { return time.Unix(unix, 0) }
+}
+
+func TestIssue_Validity(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		claims   map[string]any
+		validity go_sd_jwt.Validity
+		iat      any
+		nbf      any
+		exp      any
+	}{
+		{
+			name:     "zero value",
+			validity: go_sd_jwt.Validity{},
+		},
+		{
+			name:     "clock",
+			validity: go_sd_jwt.Validity{Clock: fixedClock(1700000123)},
+			iat:      float64(1700000123),
+		},
+		{
+			name:     "lifetime and not before",
+			validity: go_sd_jwt.Validity{Clock: fixedClock(1700000123), Lifetime: 24 * time.Hour, NotBefore: true},
+			iat:      float64(1700000123),
+			nbf:      float64(1700000123),
+			exp:      float64(1700086523),
+		},
+		{
+			name: "rounding",
+			validity: go_sd_jwt.Validity{
+				Clock:     fixedClock(1700000123),
+				Lifetime:  90 * time.Minute,
+				NotBefore: true,
+				Rounding:  time.Hour,
+			},
+			iat: float64(1699999200),
+			nbf: float64(1699999200),
+			exp: float64(1700002800),
+		},
+		{
+			name:     "provided claims are kept",
+			claims:   map[string]any{"iat": 1700000000, "nbf": 1700000500, "exp": 1700003600},
+			validity: go_sd_jwt.Validity{Clock: fixedClock(1700000123), Lifetime: 24 * time.Hour, NotBefore: true, Rounding: time.Hour},
+			iat:      float64(1700000000),
+			nbf:      float64(1700000500),
+			exp:      float64(1700003600),
+		},
+		{
+			name:     "lifetime from provided iat",
+			claims:   map[string]any{"iat": 1700000000},
+			validity: go_sd_jwt.Validity{Lifetime: time.Hour, MaxLifetime: time.Hour},
+			iat:      float64(1700000000),
+			exp:      float64(1700003600),
+		},
+		{
+			name:     "fractional dates",
+			claims:   map[string]any{"iat": 1700000000.5, "exp": 1700003600.5},
+			validity: go_sd_jwt.Validity{MaxLifetime: time.Hour, NotBefore: true},
+			iat:      1700000000.5,
+			nbf:      1700000000.5,
+			exp:      1700003600.5,
+		},
+		{
+			name:     "lifetime from fractional iat",
+			claims:   map[string]any{"iat": 1700000000.5},
+			validity: go_sd_jwt.Validity{Lifetime: time.Hour},
+			iat:      1700000000.5,
+			exp:      float64(1700003600),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuanceClaims()
+			for k, v := range tt.claims {
+				claims[k] = v
+			}
+			sdJwt, err := go_sd_jwt.Issue(claims, go_sd_jwt.IssuanceOptions{Signer: signer, Validity: tt.validity})
+			require.NoError(t, err)
+
+			for name, expected := range map[string]any{"iat": tt.iat, "nbf": tt.nbf, "exp": tt.exp} {
+				if expected == nil {
+					assert.NotContains(t, sdJwt.Body, name)
+				} else {
+					assert.Equal(t, expected, sdJwt.Body[name], name)
+				}
+			}
+		})
+	}
+}
+
+func TestIssue_ValidityErrors(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		claims   map[string]any
+		validity go_sd_jwt.Validity
+		err      string
+	}{
+		{
+			name:     "negative lifetime",
+			validity: go_sd_jwt.Validity{Lifetime: -time.Hour},
+			err:      "invalid issuance: validity durations must not be negative",
+		},
+		{
+			name:     "lifetime longer than max lifetime",
+			validity: go_sd_jwt.Validity{Lifetime: 2 * time.Hour, MaxLifetime: time.Hour},
+			err:      "invalid issuance: lifetime must not be longer than the maximum lifetime",
+		},
+		{
+			name:     "fractional rounding",
+			validity: go_sd_jwt.Validity{Rounding: 1500 * time.Millisecond},
+			err:      "invalid issuance: rounding must be a whole number of seconds",
+		},
+		{
+			name:     "lifetime shorter than rounding",
+			validity: go_sd_jwt.Validity{Lifetime: time.Minute, Rounding: time.Hour},
+			err:      "invalid issuance: lifetime must not be shorter than the rounding",
+		},
+		{
+			name:     "missing exp with max lifetime",
+			validity: go_sd_jwt.Validity{MaxLifetime: time.Hour},
+			err:      "invalid issuance: exp claim is required when a maximum lifetime is set",
+		},
+		{
+			name:     "exp beyond max lifetime",
+			claims:   map[string]any{"iat": 1700000000, "exp": 1700007200},
+			validity: go_sd_jwt.Validity{MaxLifetime: time.Hour},
+			err:      "invalid issuance: exp claim must not be more than 1h0m0s after iat",
+		},
+		{
+			name:     "fractional exp beyond max lifetime",
+			claims:   map[string]any{"iat": 1700000000, "exp": 1700003600.5},
+			validity: go_sd_jwt.Validity{MaxLifetime: time.Hour},
+			err:      "invalid issuance: exp claim must not be more than 1h0m0s after iat",
+		},
+		{
+			name:     "exp before iat",
+			claims:   map[string]any{"iat": 1700000000, "exp": 1600000000},
+			validity: go_sd_jwt.Validity{Lifetime: time.Hour},
+			err:      "invalid issuance: exp claim must be after iat",
+		},
+		{
+			name:     "non numeric exp",
+			claims:   map[string]any{"exp": "tomorrow"},
+			validity: go_sd_jwt.Validity{MaxLifetime: time.Hour},
+			err:      "invalid issuance: exp claim must be a numeric date",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuanceClaims()
+			for k, v := range tt.claims {
+				claims[k] = v
+			}
+			_, err := go_sd_jwt.Issue(claims, go_sd_jwt.IssuanceOptions{Signer: signer, Validity: tt.validity})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestValidity_Now(t *testing.T) {
+	assert.Equal(t, time.Unix(1700000123, 0), go_sd_jwt.Validity{Clock: fixedClock(1700000123)}.Now())
+	assert.Equal(t, time.Unix(1699920000, 0), go_sd_jwt.Validity{Clock: fixedClock(1700000123), Rounding: 24 * time.Hour}.Now())
+	assert.WithinDuration(t, time.Now(), go_sd_jwt.Validity{}.Now(), time.Second)
+}
+
+func TestVerify_RequireExpiry(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	withoutExp, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{Signer: signer})
+	require.NoError(t, err)
+	require.NoError(t, withoutExp.Verify(go_sd_jwt.VerificationOptions{ValidateExpiry: true}))
+
+	err = withoutExp.Verify(go_sd_jwt.VerificationOptions{RequireExpiry: true})
+	require.Error(t, err)
+	assert.Equal(t, "invalid token: exp claim is required", err.Error())
+
+	withExp, err := go_sd_jwt.Issue(issuanceClaims(), go_sd_jwt.IssuanceOptions{
+		Signer:   signer,
+		Validity: go_sd_jwt.Validity{Lifetime: time.Hour},
+	})
+	require.NoError(t, err)
+	assert.NoError(t, withExp.Verify(go_sd_jwt.VerificationOptions{RequireExpiry: true, ValidateExpiry: true}))
+
+	// NumericDate values may be fractional
+	withExp.Body["exp"] = float64(time.Now().Add(time.Hour).Unix()) + 0.5
+	assert.NoError(t, withExp.Verify(go_sd_jwt.VerificationOptions{RequireExpiry: true}))
+
+	withExp.Body["exp"] = "never"
+	err = withExp.Verify(go_sd_jwt.VerificationOptions{RequireExpiry: true})
+	require.Error(t, err)
+	assert.Equal(t, "invalid token: exp claim must be a numeric date", err.Error())
+}
@@ -0,0 +1,128 @@
+package vcdm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/utils"
+)
+
+// IssuanceOptions configures how Issue secures a credential. They are passed to go_sd_jwt.Issue, Typ must be empty or
+// vc+sd-jwt and Cty defaults to vc. When SelectivelyDisclosable is empty, every member of every credentialSubject is
+// made selectively disclosable.
+type IssuanceOptions struct {
+	go_sd_jwt.IssuanceOptions
+}
+
+// Issue secures a VCDM 2.0 credential as an SD-JWT.
+// The credential must list ContextV2 first in @context, include VerifiableCredential in type, identify its issuer and
+// contain a credentialSubject, and validFrom and validUntil must be dateTimeStamps. The iss, nbf and exp claims are
+// added from issuer, validFrom and validUntil, and iat is set to opts.Validity.Now(). An error is returned if any of the
+// PlaintextMembers is matched by a path in opts.SelectivelyDisclosable, or if a provided iss, nbf or exp claim does not
+// match its credential member. The provided credential map is not modified.
+func Issue(credential map[string]any, opts IssuanceOptions) (*go_sd_jwt.SdJwt, error) {
+	b, err := json.Marshal(credential)
+	if err != nil {
+		return nil, fmt.Errorf("%wfailed to marshal credential: %s", e.ErrInvalidIssuance, err.Error())
+	}
+	// decoded with json.Number values so integers beyond 2^53 are issued unchanged
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var claims map[string]any
+	if err := decoder.Decode(&claims); err != nil {
+		return nil, fmt.Errorf("%wfailed to parse credential: %s", e.ErrInvalidIssuance, err.Error())
+	}
+
+	parsed, err := parseCredential(claims)
+	if err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+	}
+
+	if err := addJwtClaim(claims, "iss", parsed.Issuer.ID); err != nil {
+		return nil, err
+	}
+	if parsed.ValidFrom != nil {
+		if err := addDateClaim(claims, "nbf", *parsed.ValidFrom); err != nil {
+			return nil, err
+		}
+	}
+	if parsed.ValidUntil != nil {
+		if err := addDateClaim(claims, "exp", *parsed.ValidUntil); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := claims["iat"]; !ok {
+		claims["iat"] = opts.Validity.Now().Unix()
+	}
+
+	issuance := opts.IssuanceOptions
+	if len(issuance.SelectivelyDisclosable) == 0 {
+		issuance.SelectivelyDisclosable = subjectPaths(claims["credentialSubject"])
+	}
+	for _, path := range issuance.SelectivelyDisclosable {
+		claimPath, err := go_sd_jwt.ParseClaimPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w%s", e.ErrInvalidIssuance, err.Error())
+		}
+		if name, ok := claimPath[0].(string); ok && slices.Contains(PlaintextMembers, name) {
+			return nil, fmt.Errorf("%wmember %s must not be selectively disclosable", e.ErrInvalidIssuance, name)
+		}
+	}
+
+	if issuance.Typ == "" {
+		issuance.Typ = Typ
+	} else if issuance.Typ != Typ {
+		return nil, fmt.Errorf("%wtyp header must be %s, got %s", e.ErrInvalidIssuance, Typ, issuance.Typ)
+	}
+	if issuance.Cty == "" {
+		issuance.Cty = Cty
+	}
+	return go_sd_jwt.Issue(claims, issuance)
+}
+
+// addJwtClaim adds a JWT claim mirroring a credential member, or checks a provided claim matches it
+func addJwtClaim(claims map[string]any, name string, value any) error {
+	if existing, ok := claims[name]; ok {
+		if existing != value {
+			return fmt.Errorf("%w%s claim does not match the credential", e.ErrInvalidIssuance, name)
+		}
+		return nil
+	}
+	claims[name] = value
+	return nil
+}
+
+// addDateClaim adds a numeric date JWT claim mirroring a credential member, or checks a provided claim matches it
+func addDateClaim(claims map[string]any, name string, t time.Time) error {
+	if existing, ok := claims[name]; ok {
+		if n, ok := utils.NumericDate(existing); !ok || n != float64(t.Unix()) {
+			return fmt.Errorf("%w%s claim does not match the credential", e.ErrInvalidIssuance, name)
+		}
+		return nil
+	}
+	claims[name] = t.Unix()
+	return nil
+}
+
+// subjectPaths returns the paths of every member of the credentialSubject object or objects
+func subjectPaths(subject any) []string {
+	var paths []string
+	switch s := subject.(type) {
+	case map[string]any:
+		for name := range s {
+			paths = append(paths, go_sd_jwt.ClaimPath{"credentialSubject", name}.String())
+		}
+	case []any:
+		for i, element := range s {
+			for name := range element.(map[string]any) {
+				paths = append(paths, go_sd_jwt.ClaimPath{"credentialSubject", i, name}.String())
+			}
+		}
+	}
+	return paths
+}
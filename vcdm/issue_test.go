@@ -0,0 +1,180 @@
+package vcdm_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/vcdm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func credential() map[string]any {
+	return map[string]any{
+		"@context":   []string{vcdm.ContextV2, "https://www.w3.org/ns/credentials/examples/v2"},
+		"id":         "http://university.example/credentials/3732",
+		"type":       []string{"VerifiableCredential", "ExampleDegreeCredential"},
+		"issuer":     map[string]any{"id": "https://university.example/issuers/565049", "name": "Example University"},
+		"validFrom":  "2010-01-01T19:23:24Z",
+		"validUntil": "2040-01-01T19:23:24Z",
+		"credentialSubject": map[string]any{
+			"id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			"degree": map[string]any{
+				"type": "ExampleBachelorDegree",
+				"name": "Bachelor of Science and Arts",
+			},
+		},
+	}
+}
+
+func TestIssue(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	sdJwt, err := vcdm.Issue(credential(), vcdm.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, vcdm.Typ, *sdJwt.Typ())
+	assert.Equal(t, vcdm.Cty, sdJwt.Head["cty"])
+	assert.Equal(t, "https://university.example/issuers/565049", sdJwt.Body["iss"])
+	assert.Equal(t, float64(1262373804), sdJwt.Body["nbf"])
+	assert.Equal(t, float64(2209058604), sdJwt.Body["exp"])
+	assert.Contains(t, sdJwt.Body, "iat")
+	for _, name := range vcdm.PlaintextMembers {
+		assert.Contains(t, sdJwt.Body, name)
+	}
+
+	subject := sdJwt.Body["credentialSubject"].(map[string]any)
+	assert.NotContains(t, subject, "id")
+	assert.NotContains(t, subject, "degree")
+	assert.Len(t, subject["_sd"], 2)
+}
+
+func TestIssue_LargeIntegers(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	// 9007199254740993 is 2^53 + 1, which cannot be represented as a float64
+	c := credential()
+	c["credentialSubject"].(map[string]any)["studentNumber"] = int64(9007199254740993)
+	c["nbf"] = 1262373804
+	sdJwt, err := vcdm.Issue(c, vcdm.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			SelectivelyDisclosable: []string{"credentialSubject.studentNumber"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sdJwt.Disclosures, 1)
+	value, err := base64.RawURLEncoding.DecodeString(sdJwt.Disclosures[0].EncodedValue)
+	require.NoError(t, err)
+	assert.Contains(t, string(value), `,9007199254740993]`)
+}
+
+func TestIssue_SelectivelyDisclosable(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	c := credential()
+	c["credentialSubject"] = []map[string]any{
+		{"id": "did:example:1", "name": "Alice"},
+		{"id": "did:example:2", "name": "Bob"},
+	}
+	sdJwt, err := vcdm.Issue(c, vcdm.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{
+			Signer:                 issuerSigner,
+			SelectivelyDisclosable: []string{"credentialSubject[*].name"},
+		},
+	})
+	require.NoError(t, err)
+
+	subjects := sdJwt.Body["credentialSubject"].([]any)
+	for _, s := range subjects {
+		assert.Contains(t, s, "id")
+		assert.NotContains(t, s, "name")
+	}
+	assert.Len(t, sdJwt.Disclosures, 2)
+}
+
+func TestIssue_Errors(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(c map[string]any)
+		paths  []string
+		typ    string
+		err    string
+	}{
+		{
+			name:   "missing base context",
+			modify: func(c map[string]any) { c["@context"] = []string{"https://www.w3.org/2018/credentials/v1"} },
+			err:    "invalid issuance: @context must be an array whose first entry is https://www.w3.org/ns/credentials/v2",
+		},
+		{
+			name:   "missing VerifiableCredential type",
+			modify: func(c map[string]any) { c["type"] = "ExampleDegreeCredential" },
+			err:    "invalid issuance: type must include VerifiableCredential",
+		},
+		{
+			name:   "issuer without id",
+			modify: func(c map[string]any) { c["issuer"] = map[string]any{"name": "Example University"} },
+			err:    "invalid issuance: issuer must be a URL or an object with an id",
+		},
+		{
+			name:   "invalid validFrom",
+			modify: func(c map[string]any) { c["validFrom"] = "2010-01-01" },
+			err:    "invalid issuance: validFrom must be a dateTimeStamp",
+		},
+		{
+			name:   "validUntil before validFrom",
+			modify: func(c map[string]any) { c["validUntil"] = "2009-01-01T00:00:00Z" },
+			err:    "invalid issuance: validUntil must not be before validFrom",
+		},
+		{
+			name:   "missing credentialSubject",
+			modify: func(c map[string]any) { delete(c, "credentialSubject") },
+			err:    "invalid issuance: credentialSubject must be an object or an array of objects",
+		},
+		{
+			name:   "conflicting iss",
+			modify: func(c map[string]any) { c["iss"] = "https://other.example" },
+			err:    "invalid issuance: iss claim does not match the credential",
+		},
+		{
+			name:  "selectively disclosable type",
+			paths: []string{"credentialSubject.degree", "type"},
+			err:   "invalid issuance: member type must not be selectively disclosable",
+		},
+		{
+			name: "wrong typ",
+			typ:  "vc+ld+json+sd-jwt",
+			err:  "invalid issuance: typ header must be vc+sd-jwt, got vc+ld+json+sd-jwt",
+		},
+		{
+			name:  "selectively disclosable validUntil",
+			paths: []string{"validUntil"},
+			err:   "invalid issuance: member validUntil must not be selectively disclosable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := credential()
+			if tt.modify != nil {
+				tt.modify(c)
+			}
+			_, err := vcdm.Issue(c, vcdm.IssuanceOptions{
+				IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner, SelectivelyDisclosable: tt.paths, Typ: tt.typ},
+			})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
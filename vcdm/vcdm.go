@@ -0,0 +1,171 @@
+// Package vcdm secures W3C Verifiable Credentials Data Model 2.0 credentials with SD-JWT, as described by Securing
+// Verifiable Credentials using JOSE and COSE. The credential is the payload of the issuer JWT: @context, type and the
+// other credential members stay in plaintext while the members of credentialSubject are selectively disclosable. The
+// issuer, validFrom and validUntil members are mirrored into the iss, nbf and exp claims so the JWT checks of
+// SdJwt.Verify apply to them.
+package vcdm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// Typ is the typ header of a VCDM 2.0 credential secured with SD-JWT
+	Typ = "vc+sd-jwt"
+	// Cty is the cty header of a VCDM 2.0 credential secured with SD-JWT
+	Cty = "vc"
+	// ContextV2 is the base context every VCDM 2.0 credential must list first in @context
+	ContextV2 = "https://www.w3.org/ns/credentials/v2"
+	// TypeVerifiableCredential is the type every credential must include
+	TypeVerifiableCredential = "VerifiableCredential"
+)
+
+// PlaintextMembers lists the credential members which must never be selectively disclosable
+var PlaintextMembers = []string{"@context", "type", "issuer", "validFrom", "validUntil"}
+
+// Credential is a verified VCDM 2.0 credential with the credential members split out into typed fields. Claims
+// contains all other members, e.g. credentialStatus, credentialSchema or evidence.
+type Credential struct {
+	// Context holds the entries of @context, each either a URL or an object
+	Context    []any
+	ID         string
+	Type       []string
+	Name       string
+	Issuer     Issuer
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+	// CredentialSubject holds the disclosed members of each subject, a single credentialSubject object is returned as
+	// a single entry
+	CredentialSubject []map[string]any
+	Claims            map[string]any
+}
+
+// Issuer is the issuer of a credential, provided either as a URL or as an object with an id
+type Issuer struct {
+	ID string
+	// Properties holds the other members of an issuer object, e.g. name or description
+	Properties map[string]any
+}
+
+var credentialMembers = []string{"@context", "id", "type", "name", "issuer", "validFrom", "validUntil", "credentialSubject"}
+
+// jwtClaims are the JWT claims which may accompany the credential members in the payload
+var jwtClaims = []string{"iss", "sub", "iat", "nbf", "exp", "cnf", "jti"}
+
+// parseCredential checks the structure of the credential members and returns them as a Credential
+func parseCredential(claims map[string]any) (*Credential, error) {
+	credential := &Credential{Claims: map[string]any{}}
+
+	context, ok := claims["@context"].([]any)
+	if !ok || len(context) == 0 || context[0] != ContextV2 {
+		return nil, fmt.Errorf("@context must be an array whose first entry is %s", ContextV2)
+	}
+	credential.Context = context
+
+	if v, ok := claims["id"]; ok {
+		if credential.ID, ok = v.(string); !ok {
+			return nil, errors.New("id must be a string")
+		}
+	}
+	if v, ok := claims["name"]; ok {
+		if credential.Name, ok = v.(string); !ok {
+			return nil, errors.New("name must be a string")
+		}
+	}
+
+	types, err := stringOrArray(claims["type"])
+	if err != nil {
+		return nil, errors.New("type must be a string or an array of strings")
+	}
+	if !slices.Contains(types, TypeVerifiableCredential) {
+		return nil, fmt.Errorf("type must include %s", TypeVerifiableCredential)
+	}
+	credential.Type = types
+
+	switch issuer := claims["issuer"].(type) {
+	case string:
+		credential.Issuer.ID = issuer
+	case map[string]any:
+		credential.Issuer.ID, _ = issuer["id"].(string)
+		credential.Issuer.Properties = map[string]any{}
+		for k, v := range issuer {
+			if k != "id" {
+				credential.Issuer.Properties[k] = v
+			}
+		}
+	}
+	if credential.Issuer.ID == "" {
+		return nil, errors.New("issuer must be a URL or an object with an id")
+	}
+
+	if credential.ValidFrom, err = dateTimeStamp(claims, "validFrom"); err != nil {
+		return nil, err
+	}
+	if credential.ValidUntil, err = dateTimeStamp(claims, "validUntil"); err != nil {
+		return nil, err
+	}
+	if credential.ValidFrom != nil && credential.ValidUntil != nil && credential.ValidUntil.Before(*credential.ValidFrom) {
+		return nil, errors.New("validUntil must not be before validFrom")
+	}
+
+	switch subject := claims["credentialSubject"].(type) {
+	case map[string]any:
+		credential.CredentialSubject = []map[string]any{subject}
+	case []any:
+		for _, s := range subject {
+			m, ok := s.(map[string]any)
+			if !ok {
+				return nil, errors.New("credentialSubject must be an object or an array of objects")
+			}
+			credential.CredentialSubject = append(credential.CredentialSubject, m)
+		}
+	}
+	if len(credential.CredentialSubject) == 0 {
+		return nil, errors.New("credentialSubject must be an object or an array of objects")
+	}
+
+	for k, v := range claims {
+		if !slices.Contains(credentialMembers, k) && !slices.Contains(jwtClaims, k) {
+			credential.Claims[k] = v
+		}
+	}
+	return credential, nil
+}
+
+func dateTimeStamp(claims map[string]any, name string) (*time.Time, error) {
+	v, ok := claims[name]
+	if !ok {
+		return nil, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a dateTimeStamp", name)
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a dateTimeStamp", name)
+	}
+	return &t, nil
+}
+
+func stringOrArray(v any) ([]string, error) {
+	switch value := v.(type) {
+	case string:
+		return []string{value}, nil
+	case []any:
+		result := make([]string, len(value))
+		for i, element := range value {
+			s, ok := element.(string)
+			if !ok {
+				return nil, errors.New("not a string")
+			}
+			result[i] = s
+		}
+		return result, nil
+	default:
+		return nil, errors.New("not a string or array")
+	}
+}
@@ -0,0 +1,95 @@
+package vcdm
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/utils"
+)
+
+// VerificationOptions configures how Verify checks a credential.
+// The embedded VerificationOptions are passed to SdJwt.Verify. ValidateExpiry and ValidateNotBefore also apply to
+// validUntil and validFrom.
+type VerificationOptions struct {
+	go_sd_jwt.VerificationOptions
+	// ExpectedIssuer, when set, must match the id of the issuer
+	ExpectedIssuer string
+	// ExpectedType, when set, must be included in type
+	ExpectedType string
+}
+
+// Verify verifies a VCDM 2.0 credential secured as an SD-JWT with SdJwt.Verify and returns the disclosed credential.
+// In addition to the checks configured through opts, the typ header must be vc+sd-jwt, none of the PlaintextMembers
+// may be selectively disclosed, the credential members must be valid and the iss, nbf and exp claims, where present,
+// must match issuer, validFrom and validUntil.
+func Verify(sdJwt *go_sd_jwt.SdJwt, opts VerificationOptions) (*Credential, error) {
+	if typ := sdJwt.Typ(); typ == nil || *typ != Typ {
+		return nil, fmt.Errorf("%wtyp header must be %s", e.ErrInvalidToken, Typ)
+	}
+
+	if err := sdJwt.Verify(opts.VerificationOptions); err != nil {
+		return nil, err
+	}
+
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	if err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidToken, err.Error())
+	}
+	for _, name := range PlaintextMembers {
+		if _, ok := sdJwt.Body[name]; !ok {
+			if _, ok := disclosed[name]; ok {
+				return nil, fmt.Errorf("%wmember %s must not be selectively disclosable", e.ErrInvalidToken, name)
+			}
+		}
+	}
+
+	credential, err := parseCredential(disclosed)
+	if err != nil {
+		return nil, fmt.Errorf("%w%s", e.ErrInvalidToken, err.Error())
+	}
+
+	if iss, ok := disclosed["iss"]; ok && iss != credential.Issuer.ID {
+		return nil, fmt.Errorf("%wiss claim does not match issuer %s", e.ErrInvalidToken, credential.Issuer.ID)
+	}
+	if err := matchDate(disclosed, "nbf", credential.ValidFrom); err != nil {
+		return nil, err
+	}
+	if err := matchDate(disclosed, "exp", credential.ValidUntil); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if opts.ValidateNotBefore && credential.ValidFrom != nil && now.Before(*credential.ValidFrom) {
+		return nil, fmt.Errorf("%wcredential is not yet valid", e.ErrInvalidToken)
+	}
+	if opts.ValidateExpiry && credential.ValidUntil != nil && now.After(*credential.ValidUntil) {
+		return nil, fmt.Errorf("%wcredential has expired", e.ErrInvalidToken)
+	}
+
+	if opts.ExpectedIssuer != "" && credential.Issuer.ID != opts.ExpectedIssuer {
+		return nil, fmt.Errorf("%wissuer mismatch: expected %s", e.ErrInvalidToken, opts.ExpectedIssuer)
+	}
+	if opts.ExpectedType != "" && !slices.Contains(credential.Type, opts.ExpectedType) {
+		return nil, fmt.Errorf("%wtype mismatch: expected %s", e.ErrInvalidToken, opts.ExpectedType)
+	}
+	return credential, nil
+}
+
+// matchDate checks a numeric date claim matches the credential member it mirrors, to the second
+func matchDate(claims map[string]any, name string, member *time.Time) error {
+	v, ok := claims[name]
+	if !ok || member == nil {
+		return nil
+	}
+	n, ok := utils.NumericDate(v)
+	if !ok {
+		return fmt.Errorf("%w%s claim must be a numeric date", e.ErrInvalidToken, name)
+	}
+	if int64(n) != member.Unix() {
+		return fmt.Errorf("%w%s claim does not match the credential", e.ErrInvalidToken, name)
+	}
+	return nil
+}
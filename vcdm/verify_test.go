@@ -0,0 +1,166 @@
+package vcdm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/vcdm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	c := credential()
+	c["credentialStatus"] = map[string]any{"id": "https://university.example/status/24#94567", "type": "BitstringStatusListEntry"}
+	sdJwt, err := vcdm.Issue(c, vcdm.IssuanceOptions{
+		IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+	})
+	require.NoError(t, err)
+
+	// the holder discloses the degree but not the subject id
+	sdJwt.Disclosures, err = sdJwt.SelectDisclosures("credentialSubject.degree")
+	require.NoError(t, err)
+
+	verified, err := vcdm.Verify(sdJwt, vcdm.VerificationOptions{
+		VerificationOptions: go_sd_jwt.VerificationOptions{
+			IssuerKey:         issuerSigner.Public(),
+			ValidateExpiry:    true,
+			ValidateNotBefore: true,
+		},
+		ExpectedIssuer: "https://university.example/issuers/565049",
+		ExpectedType:   "ExampleDegreeCredential",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{vcdm.ContextV2, "https://www.w3.org/ns/credentials/examples/v2"}, verified.Context)
+	assert.Equal(t, "http://university.example/credentials/3732", verified.ID)
+	assert.Equal(t, []string{"VerifiableCredential", "ExampleDegreeCredential"}, verified.Type)
+	assert.Equal(t, vcdm.Issuer{
+		ID:         "https://university.example/issuers/565049",
+		Properties: map[string]any{"name": "Example University"},
+	}, verified.Issuer)
+	assert.Equal(t, time.Date(2010, time.January, 1, 19, 23, 24, 0, time.UTC), *verified.ValidFrom)
+	assert.Equal(t, time.Date(2040, time.January, 1, 19, 23, 24, 0, time.UTC), *verified.ValidUntil)
+	assert.Equal(t, []map[string]any{{
+		"degree": map[string]any{"type": "ExampleBachelorDegree", "name": "Bachelor of Science and Arts"},
+	}}, verified.CredentialSubject)
+	assert.Equal(t, map[string]any{"credentialStatus": c["credentialStatus"]}, verified.Claims)
+}
+
+func TestVerify_Errors(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(c map[string]any)
+		opts   vcdm.VerificationOptions
+		err    string
+	}{
+		{
+			name: "expired",
+			modify: func(c map[string]any) {
+				c["validUntil"] = "2011-01-01T00:00:00Z"
+			},
+			opts: vcdm.VerificationOptions{VerificationOptions: go_sd_jwt.VerificationOptions{ValidateExpiry: true}},
+			err:  "invalid token: token has expired",
+		},
+		{
+			name: "not yet valid",
+			modify: func(c map[string]any) {
+				c["validFrom"] = "2039-01-01T00:00:00Z"
+			},
+			opts: vcdm.VerificationOptions{VerificationOptions: go_sd_jwt.VerificationOptions{ValidateNotBefore: true}},
+			err:  "invalid token: token is not yet valid",
+		},
+		{
+			name: "issuer mismatch",
+			opts: vcdm.VerificationOptions{ExpectedIssuer: "https://other.example"},
+			err:  "invalid token: issuer mismatch: expected https://other.example",
+		},
+		{
+			name: "type mismatch",
+			opts: vcdm.VerificationOptions{ExpectedType: "ExampleMasterDegreeCredential"},
+			err:  "invalid token: type mismatch: expected ExampleMasterDegreeCredential",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := credential()
+			if tt.modify != nil {
+				tt.modify(c)
+			}
+			sdJwt, err := vcdm.Issue(c, vcdm.IssuanceOptions{
+				IssuanceOptions: go_sd_jwt.IssuanceOptions{Signer: issuerSigner},
+			})
+			require.NoError(t, err)
+
+			_, err = vcdm.Verify(sdJwt, tt.opts)
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestVerify_MismatchedJwtClaims(t *testing.T) {
+	issuerSigner, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		typ    string
+		claims map[string]any
+		paths  []string
+		err    string
+	}{
+		{
+			name: "typ",
+			typ:  "example+sd-jwt",
+			err:  "invalid token: typ header must be vc+sd-jwt",
+		},
+		{
+			name:   "iss",
+			claims: map[string]any{"iss": "https://other.example"},
+			err:    "invalid token: iss claim does not match issuer https://university.example/issuers/565049",
+		},
+		{
+			name:   "exp",
+			claims: map[string]any{"exp": 2209058605},
+			err:    "invalid token: exp claim does not match the credential",
+		},
+		{
+			name:  "selectively disclosed type",
+			paths: []string{"type"},
+			err:   "invalid token: member type must not be selectively disclosable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// issued without the vcdm profile, as a non-conforming issuer would
+			typ := vcdm.Typ
+			if tt.typ != "" {
+				typ = tt.typ
+			}
+			c := credential()
+			for k, v := range tt.claims {
+				c[k] = v
+			}
+			sdJwt, err := go_sd_jwt.Issue(c, go_sd_jwt.IssuanceOptions{
+				Signer:                 issuerSigner,
+				Typ:                    typ,
+				SelectivelyDisclosable: tt.paths,
+			})
+			require.NoError(t, err)
+
+			_, err = vcdm.Verify(sdJwt, vcdm.VerificationOptions{})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
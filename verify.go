@@ -8,11 +8,11 @@ import (
 	"strings"
 	"time"
 
-	"github.com/MichaelFraser99/go-jose/jwk"
 	"github.com/MichaelFraser99/go-jose/jws"
 	josemodel "github.com/MichaelFraser99/go-jose/model"
 
 	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+	"github.com/MichaelFraser99/go-sd-jwt/v2/internal/utils"
 )
 
 // VerificationOptions configures what aspects of the SD-JWT are verified.
@@ -24,16 +24,42 @@ type VerificationOptions struct {
 	ExpectedAudience     *string
 	ExpectedNonce        *string
 	VerifyKBJwtSignature bool
+	// HolderKeyResolver resolves the holder key for a cnf claim containing a kid when verifying the kb-jwt signature
+	HolderKeyResolver HolderKeyResolver
+	// UnderstoodHeaders lists the extension header parameters the caller processes. Tokens whose crit header lists any
+	// other header parameter are rejected.
+	UnderstoodHeaders []string
+	// RequireExpiry rejects tokens without an exp claim holding a valid NumericDate, which ValidateExpiry alone accepts
+	RequireExpiry bool
 }
 
 // Verify performs cryptographic and semantic verification of the SD-JWT based on the provided options.
+// The typ, kid, cty, x5c and crit header parameters must be well formed, and tokens with a crit header listing a header
+// parameter not in opts.UnderstoodHeaders are always rejected.
 func (s *SdJwt) Verify(opts VerificationOptions) error {
+	if err := validateHeader(s.Head); err != nil {
+		return fmt.Errorf("%winvalid header: %s", e.ErrInvalidToken, err.Error())
+	}
+	if err := checkCritUnderstood(s.Head, opts.UnderstoodHeaders); err != nil {
+		return fmt.Errorf("%w%s", e.ErrInvalidToken, err.Error())
+	}
+
 	if opts.IssuerKey != nil {
 		if err := s.verifyIssuerSignature(opts.IssuerKey); err != nil {
 			return err
 		}
 	}
 
+	if opts.RequireExpiry {
+		exp, ok := s.Body["exp"]
+		if !ok {
+			return fmt.Errorf("%wexp claim is required", e.ErrInvalidToken)
+		}
+		if _, ok := utils.NumericDate(exp); !ok {
+			return fmt.Errorf("%wexp claim must be a numeric date", e.ErrInvalidToken)
+		}
+	}
+
 	if opts.ValidateExpiry {
 		if err := s.validateExpiry(); err != nil {
 			return err
@@ -60,7 +86,7 @@ func (s *SdJwt) Verify(opts VerificationOptions) error {
 		}
 
 		if opts.VerifyKBJwtSignature {
-			if err := s.verifyKBJwtSignature(); err != nil {
+			if err := s.verifyKBJwtSignature(opts.HolderKeyResolver); err != nil {
 				return err
 			}
 		}
@@ -133,20 +159,13 @@ func (s *SdJwt) validateNotBefore() error {
 	return nil
 }
 
-func (s *SdJwt) verifyKBJwtSignature() error {
-	cnf, ok := s.Body["cnf"].(map[string]any)
-	if !ok {
-		return fmt.Errorf("%w'cnf' claim missing or invalid in issuer JWT", e.ErrInvalidToken)
-	}
-
-	jwkMap, ok := cnf["jwk"].(map[string]any)
-	if !ok {
-		return fmt.Errorf("%w'jwk' missing or invalid in 'cnf' claim", e.ErrInvalidToken)
-	}
-
-	holderKey, err := jwk.PublicFromJwk(jwkMap)
+func (s *SdJwt) verifyKBJwtSignature(resolver HolderKeyResolver) error {
+	cnf, err := s.Confirmation()
 	if err != nil {
-		return fmt.Errorf("%wfailed to parse holder public key from cnf.jwk: %w", e.ErrInvalidToken, err)
+		return fmt.Errorf("%w%s", e.ErrInvalidToken, err.Error())
+	}
+	if cnf == nil {
+		return fmt.Errorf("%w'cnf' claim missing or invalid in issuer JWT", e.ErrInvalidToken)
 	}
 
 	kbParts := strings.Split(s.KbJwt.Token, ".")
@@ -164,6 +183,12 @@ func (s *SdJwt) verifyKBJwtSignature() error {
 		return fmt.Errorf("%wfailed to parse kb-jwt header: %w", e.ErrInvalidToken, err)
 	}
 
+	kbHeadJwk, _ := kbHead["jwk"].(map[string]any)
+	holderKey, err := cnf.resolveHolderKey(resolver, kbHeadJwk)
+	if err != nil {
+		return fmt.Errorf("%wfailed to determine holder public key: %w", e.ErrInvalidToken, err)
+	}
+
 	kbAlgStr, ok := kbHead["alg"].(string)
 	if !ok {
 		return fmt.Errorf("%wmissing or invalid 'alg' in kb-jwt header", e.ErrInvalidToken)
@@ -237,3 +237,74 @@ func TestVerify_KBJwtAudienceAndNonce(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestVerify_InvalidHeader(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	body := map[string]any{"sub": "user_42", "_sd_alg": "sha-256"}
+
+	tests := []struct {
+		name string
+		head map[string]any
+		err  string
+	}{
+		{
+			name: "kid is not a string",
+			head: map[string]any{"alg": "ES256", "kid": 1},
+			err:  "invalid token: invalid header: header kid must be a string",
+		},
+		{
+			name: "x5c is not an array",
+			head: map[string]any{"alg": "ES256", "x5c": "MII"},
+			err:  "invalid token: invalid header: header x5c must be a non-empty array of certificates",
+		},
+		{
+			name: "crit is empty",
+			head: map[string]any{"alg": "ES256", "crit": []any{}},
+			err:  "invalid token: invalid header: header crit must be a non-empty array of header names",
+		},
+		{
+			name: "crit lists a missing header",
+			head: map[string]any{"alg": "ES256", "crit": []any{"ext"}},
+			err:  "invalid token: invalid header: header crit lists ext which is not present in the header",
+		},
+		{
+			name: "crit lists a registered header",
+			head: map[string]any{"alg": "ES256", "kid": "key-1", "crit": []any{"kid"}},
+			err:  "invalid token: invalid header: header crit must not list the registered header kid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// parsing accepts the header, only verification checks it
+			sdJwt, err := New(buildSignedSDJWT(t, tt.head, body, signer))
+			require.NoError(t, err)
+
+			err = sdJwt.Verify(VerificationOptions{})
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func TestVerify_CriticalHeaders(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	head := map[string]any{"alg": "ES256", "ext": "value", "other": true, "crit": []any{"ext", "other"}}
+	body := map[string]any{"sub": "user_42", "_sd_alg": "sha-256"}
+
+	sdJwt, err := New(buildSignedSDJWT(t, head, body, signer))
+	require.NoError(t, err)
+
+	err = sdJwt.Verify(VerificationOptions{})
+	require.Error(t, err)
+	assert.Equal(t, "invalid token: critical header ext is not understood", err.Error())
+
+	err = sdJwt.Verify(VerificationOptions{UnderstoodHeaders: []string{"ext"}})
+	require.Error(t, err)
+	assert.Equal(t, "invalid token: critical header other is not understood", err.Error())
+
+	assert.NoError(t, sdJwt.Verify(VerificationOptions{IssuerKey: signer.Public(), UnderstoodHeaders: []string{"ext", "other"}}))
+}
@@ -0,0 +1,121 @@
+package go_sd_jwt
+
+import (
+	"fmt"
+
+	e "github.com/MichaelFraser99/go-sd-jwt/v2/internal/error"
+	"gopkg.in/yaml.v3"
+)
+
+const yamlSdTag = "!sd"
+
+// IssueFromYAML creates a new signed SD-JWT from claims written in YAML, using the format of the SD-JWT specification
+// examples where the !sd tag marks selectively disclosable claims and array elements, e.g.
+//
+//	sub: user_42
+//	!sd given_name: John
+//	nationalities:
+//	  - !sd US
+//	  - !sd DE
+//
+// The selectively disclosable claims are added to any paths already listed in opts.SelectivelyDisclosable.
+func IssueFromYAML(input []byte, opts IssuanceOptions) (*SdJwt, error) {
+	claims, paths, err := ParseYAMLClaims(input)
+	if err != nil {
+		return nil, err
+	}
+	opts.SelectivelyDisclosable = append(append([]string{}, opts.SelectivelyDisclosable...), paths...)
+	return Issue(claims, opts)
+}
+
+// ParseYAMLClaims parses claims written in YAML with !sd tags (see IssueFromYAML), returning the claims with the tags
+// removed and the claim paths of the tagged claims and array elements.
+func ParseYAMLClaims(input []byte) (map[string]any, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(input, &doc); err != nil {
+		return nil, nil, fmt.Errorf("%winvalid yaml: %s", e.ErrInvalidIssuance, err.Error())
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) != 1 || resolveYAMLAlias(doc.Content[0]).Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("%wyaml claims must be a mapping", e.ErrInvalidIssuance)
+	}
+
+	p := &yamlParser{}
+	v, err := p.value(doc.Content[0], nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v.(map[string]any), p.paths, nil
+}
+
+type yamlParser struct {
+	paths []string
+}
+
+func resolveYAMLAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+func (p *yamlParser) value(node *yaml.Node, path ClaimPath) (any, error) {
+	node = resolveYAMLAlias(node)
+	switch node.Kind {
+	case yaml.MappingNode:
+		obj := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := resolveYAMLAlias(node.Content[i]), node.Content[i+1]
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("%wclaim names must be strings at line %d", e.ErrInvalidIssuance, key.Line)
+			}
+			if _, ok := obj[key.Value]; ok {
+				return nil, fmt.Errorf("%wduplicate claim %s at line %d", e.ErrInvalidIssuance, path.Append(key.Value), key.Line)
+			}
+			if value.Tag == yamlSdTag {
+				return nil, fmt.Errorf("%wthe !sd tag must be placed on the claim name at line %d", e.ErrInvalidIssuance, value.Line)
+			}
+
+			claimPath := path.Append(key.Value)
+			v, err := p.value(value, claimPath)
+			if err != nil {
+				return nil, err
+			}
+			obj[key.Value] = v
+			if key.Tag == yamlSdTag {
+				p.paths = append(p.paths, claimPath.String())
+			}
+		}
+		return obj, nil
+	case yaml.SequenceNode:
+		arr := make([]any, len(node.Content))
+		for i, element := range node.Content {
+			elementPath := path.Append(i)
+			v, err := p.value(element, elementPath)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = v
+			if element.Tag == yamlSdTag {
+				p.paths = append(p.paths, elementPath.String())
+			}
+		}
+		return arr, nil
+	case yaml.ScalarNode:
+		scalar := *node
+		if scalar.Tag == yamlSdTag {
+			// let the value be resolved as if it were untagged
+			scalar.Tag = ""
+		}
+		if scalar.ShortTag() == "!!timestamp" {
+			// JSON has no timestamp type so dates such as birthdate: 1940-01-01 are kept as written
+			return scalar.Value, nil
+		}
+		var v any
+		if err := scalar.Decode(&v); err != nil {
+			return nil, fmt.Errorf("%winvalid value at line %d: %s", e.ErrInvalidIssuance, node.Line, err.Error())
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("%wunsupported yaml node at line %d", e.ErrInvalidIssuance, node.Line)
+	}
+}
@@ -0,0 +1,153 @@
+package go_sd_jwt_test
+
+import (
+	"testing"
+
+	"github.com/MichaelFraser99/go-jose/jws"
+	"github.com/MichaelFraser99/go-jose/model"
+	go_sd_jwt "github.com/MichaelFraser99/go-sd-jwt/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const specExampleYAML = `
+iss: https://issuer.example.com
+sub: user_42
+!sd given_name: John
+!sd family_name: Doe
+!sd email: johndoe@example.com
+!sd phone_number: +1-202-555-0101
+!sd phone_number_verified: true
+!sd address:
+  street_address: 123 Main St
+  locality: Anytown
+  region: Anystate
+  country: US
+!sd birthdate: 1940-01-01
+!sd updated_at: 1570000000
+nationalities:
+  - !sd US
+  - !sd DE
+`
+
+func TestParseYAMLClaims(t *testing.T) {
+	claims, paths, err := go_sd_jwt.ParseYAMLClaims([]byte(specExampleYAML))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"iss":                   "https://issuer.example.com",
+		"sub":                   "user_42",
+		"given_name":            "John",
+		"family_name":           "Doe",
+		"email":                 "johndoe@example.com",
+		"phone_number":          "+1-202-555-0101",
+		"phone_number_verified": true,
+		"address": map[string]any{
+			"street_address": "123 Main St",
+			"locality":       "Anytown",
+			"region":         "Anystate",
+			"country":        "US",
+		},
+		"birthdate":     "1940-01-01",
+		"updated_at":    1570000000,
+		"nationalities": []any{"US", "DE"},
+	}, claims)
+	assert.Equal(t, []string{
+		"given_name", "family_name", "email", "phone_number", "phone_number_verified", "address", "birthdate",
+		"updated_at", "nationalities[0]", "nationalities[1]",
+	}, paths)
+}
+
+func TestParseYAMLClaims_Nested(t *testing.T) {
+	input := `
+!sd address:
+  !sd street_address: Schulstr. 12
+  locality: Schulpforta
+tags: &tags
+  - !sd a
+  - !sd [1, 2]
+  - !sd {key: value}
+copy: *tags
+"!sd quoted": "!sd"
+`
+	claims, paths, err := go_sd_jwt.ParseYAMLClaims([]byte(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"address":    map[string]any{"street_address": "Schulstr. 12", "locality": "Schulpforta"},
+		"tags":       []any{"a", []any{1, 2}, map[string]any{"key": "value"}},
+		"copy":       []any{"a", []any{1, 2}, map[string]any{"key": "value"}},
+		"!sd quoted": "!sd",
+	}, claims)
+	assert.Equal(t, []string{
+		"address.street_address", "address", "tags[0]", "tags[1]", "tags[2]", "copy[0]", "copy[1]", "copy[2]",
+	}, paths)
+}
+
+func TestIssueFromYAML(t *testing.T) {
+	signer, err := jws.GetSigner(model.ES256, nil)
+	require.NoError(t, err)
+
+	sdJwt, err := go_sd_jwt.IssueFromYAML([]byte(specExampleYAML), go_sd_jwt.IssuanceOptions{Signer: signer})
+	require.NoError(t, err)
+	require.NoError(t, sdJwt.Verify(go_sd_jwt.VerificationOptions{IssuerKey: signer.Public()}))
+
+	assert.Len(t, sdJwt.Disclosures, 10)
+	assert.Len(t, sdJwt.Body["_sd"], 8)
+	assert.Equal(t, "user_42", sdJwt.Body["sub"])
+	nationalities := sdJwt.Body["nationalities"].([]any)
+	require.Len(t, nationalities, 2)
+	assert.Contains(t, nationalities[0], "...")
+
+	claims, _, err := go_sd_jwt.ParseYAMLClaims([]byte(specExampleYAML))
+	require.NoError(t, err)
+	disclosed, err := sdJwt.GetDisclosedClaims()
+	require.NoError(t, err)
+	assert.Equal(t, roundTrip(t, claims), disclosed)
+}
+
+func TestParseYAMLClaims_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{
+			name:  "invalid yaml",
+			input: "a: [",
+			err:   "invalid issuance: invalid yaml: yaml: line 1: did not find expected node content",
+		},
+		{
+			name:  "not a mapping",
+			input: "- a\n- b",
+			err:   "invalid issuance: yaml claims must be a mapping",
+		},
+		{
+			name:  "empty document",
+			input: "",
+			err:   "invalid issuance: yaml claims must be a mapping",
+		},
+		{
+			name:  "tagged value",
+			input: "given_name: !sd John",
+			err:   "invalid issuance: the !sd tag must be placed on the claim name at line 1",
+		},
+		{
+			name:  "non scalar key",
+			input: "? [a, b]\n: c",
+			err:   "invalid issuance: claim names must be strings at line 1",
+		},
+		{
+			name:  "duplicate claim",
+			input: "a: 1\n!sd a: 2",
+			err:   "invalid issuance: duplicate claim a at line 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := go_sd_jwt.ParseYAMLClaims([]byte(tt.input))
+			require.Error(t, err)
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}